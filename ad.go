@@ -0,0 +1,144 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+)
+
+// addAdditionalData hashes additionalData after any given so far. Unaligned
+// tails are buffered rather than padded, so splitting the additional data
+// across calls does not change the tag.
+func (g *gcm) addAdditionalData(additionalData []byte) {
+	if g.finalized {
+		panic(ErrFinalized)
+	}
+	if g.reversed.power != (gcmFieldElement{}) {
+		// addADReversed recomputes the hash from the state it started
+		// from, which would drop anything hashed in between.
+		panic("gcm: additional data added after reversed additional data")
+	}
+	if g.interleaved {
+		g.interleavedAD.update(g, additionalData)
+		return
+	}
+	if g.adDone {
+		panic("gcm: additional data added after data")
+	}
+
+	if g.deferAD {
+		g.lazyAD = append(g.lazyAD, additionalData...)
+		return
+	}
+	g.updateStream(additionalData)
+}
+
+// AddAdditionalData authenticates additionalData as if it had been appended
+// to the additional data given to the constructor. It must be called before
+// any plaintext is encrypted, unless the encrypter is interleaved.
+func (g *Encrypter) AddAdditionalData(additionalData []byte) {
+	g.addAdditionalData(additionalData)
+	g.additionalDataNb += uint64(len(additionalData))
+}
+
+// AddAdditionalData authenticates additionalData as if it had been appended
+// to the additional data given to the constructor. It must be called before
+// any ciphertext is decrypted, unless the decrypter is interleaved.
+func (g *Decrypter) AddAdditionalData(additionalData []byte) {
+	g.addAdditionalData(additionalData)
+	g.additionalDataNb += uint64(len(additionalData))
+}
+
+// addAdditionalDataFrom reads r to EOF and passes each chunk to
+// addAdditionalData, so its tail buffering is shared with slice-based calls.
+func (g *gcm) addAdditionalDataFrom(r io.Reader) (int64, error) {
+	buf := make([]byte, 4096)
+
+	var n int64
+	for {
+		m, err := r.Read(buf)
+		g.addAdditionalData(buf[:m])
+		n += int64(m)
+
+		if err == io.EOF {
+			return n, nil
+		}
+		if err != nil {
+			return n, err
+		}
+	}
+}
+
+// AddAdditionalDataFrom authenticates everything read from r until EOF as
+// additional data, exactly as AddAdditionalData with the same bytes would,
+// and returns the number of bytes read. It may be mixed freely with
+// AddAdditionalData. If r fails, the bytes read before the error have been
+// authenticated and the error is returned.
+func (g *Encrypter) AddAdditionalDataFrom(r io.Reader) (int64, error) {
+	n, err := g.addAdditionalDataFrom(r)
+	g.additionalDataNb += uint64(n)
+	return n, err
+}
+
+// AddAdditionalDataFrom is the decrypter's counterpart of the encrypter's
+// AddAdditionalDataFrom.
+func (g *Decrypter) AddAdditionalDataFrom(r io.Reader) (int64, error) {
+	n, err := g.addAdditionalDataFrom(r)
+	g.additionalDataNb += uint64(n)
+	return n, err
+}
+
+// addADSection hashes label and data as additional data, each preceded by
+// its length as a big-endian uint64, and returns the number of bytes hashed.
+// The framing means no two different sequences of sections hash the same
+// bytes, so sections cannot be reordered, relabeled or moved across a
+// boundary without changing the tag.
+func (g *gcm) addADSection(label string, data []byte) int {
+	var length [8]byte
+
+	binary.BigEndian.PutUint64(length[:], uint64(len(label)))
+	g.addAdditionalData(length[:])
+	g.addAdditionalData([]byte(label))
+
+	binary.BigEndian.PutUint64(length[:], uint64(len(data)))
+	g.addAdditionalData(length[:])
+	g.addAdditionalData(data)
+
+	return 2*len(length) + len(label) + len(data)
+}
+
+// AddADSection authenticates data as an additional data section named
+// label. It is hashed as label-length || label || data-length || data, with
+// both lengths big-endian uint64s, after any additional data given so far,
+// and the decrypter must add the same sections in the same order.
+func (g *Encrypter) AddADSection(label string, data []byte) {
+	g.additionalDataNb += uint64(g.addADSection(label, data))
+}
+
+// AddADSection is the decrypter's counterpart of the encrypter's
+// AddADSection.
+func (g *Decrypter) AddADSection(label string, data []byte) {
+	g.additionalDataNb += uint64(g.addADSection(label, data))
+}
+
+// newGCMEncrypterWithADFunc is like newGCMEncrypter but takes the additional
+// data from additionalData, which is called exactly once, during
+// construction, and whose result is hashed straight away. It lets callers
+// serialize metadata at the point of encryption. A nil func means no
+// additional data.
+func newGCMEncrypterWithADFunc(cipher cipher.Block, nonce []byte, additionalData func() []byte) *Encrypter {
+	return newGCMEncrypter(cipher, nonce, callADFunc(additionalData))
+}
+
+// newGCMDecrypterWithADFunc is the decrypting counterpart of
+// newGCMEncrypterWithADFunc.
+func newGCMDecrypterWithADFunc(cipher cipher.Block, nonce []byte, additionalData func() []byte) *Decrypter {
+	return newGCMDecrypter(cipher, nonce, callADFunc(additionalData))
+}
+
+func callADFunc(f func() []byte) []byte {
+	if f == nil {
+		return nil
+	}
+	return f()
+}
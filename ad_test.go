@@ -0,0 +1,249 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddAdditionalData(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+
+	ad := make([]byte, 53)
+	for i := range ad {
+		ad[i] = byte(i)
+	}
+	plaintext := []byte("plaintext after split additional data")
+
+	for _, splits := range [][]int{
+		{53},
+		{0, 53},
+		{1, 52},
+		{7, 9, 20, 17},
+		{15, 1, 16, 21},
+		{3, 0, 50},
+	} {
+		want := aead.Seal(nil, nonce, plaintext, ad)
+
+		for _, newEncrypter := range []func(cipher.Block, []byte, []byte) *Encrypter{
+			newGCMEncrypter,
+			newLazyGCMEncrypter,
+		} {
+			// The first piece goes to the constructor.
+			e := newEncrypter(block, nonce, ad[:splits[0]])
+			d := newGCMDecrypter(block, nonce, ad[:splits[0]])
+			offset := splits[0]
+			for _, n := range splits[1:] {
+				e.AddAdditionalData(ad[offset : offset+n])
+				d.AddAdditionalData(ad[offset : offset+n])
+				offset += n
+			}
+
+			got := e.Encrypt(nil, plaintext)
+			tag := e.Tag()
+			assert.Equal(t, want, append(got, tag[:]...), "splits %v", splits)
+
+			_, err := d.Decrypt(nil, got)
+			assert.Nil(t, err)
+			assert.Nil(t, d.VerifyArray(tag))
+		}
+	}
+}
+
+func TestAddAdditionalDataOnly(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, []byte("gm"))
+	e.AddAdditionalData([]byte("ac only"))
+	tag := e.Tag()
+	assert.Equal(t, aead.Seal(nil, nonce, nil, []byte("gmac only")), tag[:])
+}
+
+func TestAddAdditionalDataAfterData(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	e.Encrypt(nil, nil)
+	assert.Panics(t, func() { e.AddAdditionalData([]byte("late")) })
+
+	d := newGCMDecrypter(block, nonce, nil)
+	_, err = d.Decrypt(nil, []byte{1})
+	assert.Nil(t, err)
+	assert.Panics(t, func() { d.AddAdditionalData([]byte("late")) })
+}
+
+type frameHeader struct {
+	Version uint8
+	Length  uint16
+}
+
+func (h *frameHeader) encode() []byte {
+	return []byte{h.Version, byte(h.Length >> 8), byte(h.Length)}
+}
+
+func TestADFunc(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	header := &frameHeader{Version: 1, Length: uint16(len(decryptedPacket))}
+	calls := 0
+	adFunc := func() []byte {
+		calls++
+		return header.encode()
+	}
+
+	want := newGCMEncrypter(block, nonce, header.encode())
+	wantCiphertext := want.Encrypt(nil, decryptedPacket)
+	wantTag := want.Tag()
+
+	e := newGCMEncrypterWithADFunc(block, nonce, adFunc)
+	assert.Equal(t, 1, calls)
+
+	// The hook already ran, so later changes to the header are not covered.
+	header.Version = 2
+	assert.Equal(t, wantCiphertext, e.Encrypt(nil, decryptedPacket))
+	assert.Equal(t, wantTag, e.Tag())
+	assert.Equal(t, 1, calls)
+
+	header.Version = 1
+	d := newGCMDecrypterWithADFunc(block, nonce, adFunc)
+	_, err = d.OpenVerified(wantCiphertext, wantTag[:])
+	assert.Nil(t, err)
+
+	empty := newGCMEncrypter(block, nonce, nil)
+	assert.Equal(t, empty.Tag(), newGCMEncrypterWithADFunc(block, nonce, nil).Tag())
+}
+
+func TestAddAdditionalDataFrom(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	ad := strings.Repeat("additional data ", 600)[:9001]
+
+	for _, newEnc := range []func(ad []byte) *Encrypter{
+		func(ad []byte) *Encrypter { return newGCMEncrypter(block, nonce, ad) },
+		func(ad []byte) *Encrypter { return newLazyGCMEncrypter(block, nonce, ad) },
+	} {
+		reference := newEnc([]byte(ad))
+		reference.Encrypt(nil, decryptedPacket)
+
+		// Split at unaligned boundaries, with the reader returning one
+		// byte at a time for part of it.
+		e := newEnc(nil)
+		e.AddAdditionalData([]byte(ad[:7]))
+		n, err := e.AddAdditionalDataFrom(iotest.OneByteReader(strings.NewReader(ad[7:40])))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(33), n)
+		e.AddAdditionalData([]byte(ad[40:45]))
+		n, err = e.AddAdditionalDataFrom(strings.NewReader(ad[45:]))
+		assert.Nil(t, err)
+		assert.Equal(t, int64(len(ad)-45), n)
+		e.Encrypt(nil, decryptedPacket)
+
+		assert.Equal(t, reference.Tag(), e.Tag())
+	}
+}
+
+func TestAddAdditionalDataFromError(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	failure := errors.New("read failed")
+	d := newGCMDecrypter(block, nonce, nil)
+	n, err := d.AddAdditionalDataFrom(io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(failure)))
+	assert.Equal(t, failure, err)
+	assert.Equal(t, int64(7), n)
+
+	ad, _ := d.Stats()
+	assert.Equal(t, uint64(7), ad)
+}
+
+type adSection struct {
+	label string
+	data  []byte
+}
+
+func TestAddADSection(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	sections := []adSection{
+		{"hdr", []byte("version=1")},
+		{"meta", []byte("owner=alice")},
+		{"routing", []byte("eu-west")},
+	}
+	plaintext := []byte("sectioned plaintext")
+
+	e := newGCMEncrypter(block, nonce, nil)
+	for _, s := range sections {
+		e.AddADSection(s.label, s.data)
+	}
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	// The framing is plain additional data, so the stdlib agrees.
+	var framed []byte
+	for _, s := range sections {
+		framed = binary.BigEndian.AppendUint64(framed, uint64(len(s.label)))
+		framed = append(framed, s.label...)
+		framed = binary.BigEndian.AppendUint64(framed, uint64(len(s.data)))
+		framed = append(framed, s.data...)
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+	assert.Equal(t, aead.Seal(nil, nonce, plaintext, framed), append(ciphertext, tag[:]...))
+
+	ad, _ := e.Stats()
+	assert.Equal(t, uint64(len(framed)), ad)
+
+	verify := func(sections []adSection) error {
+		d := newGCMDecrypter(block, nonce, nil)
+		for _, s := range sections {
+			d.AddADSection(s.label, s.data)
+		}
+		_, err := d.Decrypt(nil, ciphertext)
+		assert.Nil(t, err)
+		return d.VerifyArray(tag)
+	}
+
+	assert.Nil(t, verify(sections))
+
+	for name, tampered := range map[string][]adSection{
+		"swapped data": {
+			{"hdr", sections[1].data},
+			{"meta", sections[0].data},
+			sections[2],
+		},
+		"reordered": {sections[1], sections[0], sections[2]},
+		"relabeled": {sections[0], {"metadata", sections[1].data}, sections[2]},
+		"shifted boundary": {
+			{"hdr", []byte("version=1o")},
+			{"meta", []byte("wner=alice")},
+			sections[2],
+		},
+		"label moved into data": {
+			{"hd", []byte("rversion=1")},
+			sections[1],
+			sections[2],
+		},
+		"missing": sections[:2],
+	} {
+		assert.Equal(t, ErrOpen, verify(tampered), name)
+	}
+}
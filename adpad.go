@@ -0,0 +1,52 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+// newGCMEncrypterPaddedAD is like newGCMEncrypter but pads additionalData
+// to size+1 bytes before hashing it, so the GHASH work for the additional
+// data, and the length in the final block, are the same for every
+// additionalData up to size bytes. The padding is a 0x80 byte followed by
+// zeros, as in ISO/IEC 7816-4, so additionalData that ends in zero bytes
+// still pads differently from its prefixes. It is copied without branching
+// on the length of additionalData beyond whether it is empty. The tag is
+// that of standard GCM with the padded additional data, so the decrypter
+// must use the same size. It panics if additionalData is longer than size.
+func newGCMEncrypterPaddedAD(cipher cipher.Block, nonce, additionalData []byte, size int) *Encrypter {
+	return newGCMEncrypter(cipher, nonce, padAD(additionalData, size))
+}
+
+// newGCMDecrypterPaddedAD is the decrypting counterpart of
+// newGCMEncrypterPaddedAD.
+func newGCMDecrypterPaddedAD(cipher cipher.Block, nonce, additionalData []byte, size int) *Decrypter {
+	return newGCMDecrypter(cipher, nonce, padAD(additionalData, size))
+}
+
+// padAD returns additionalData followed by 0x80 and then zeros up to
+// size+1 bytes. Every output byte costs the same in-bounds load and mask, as
+// in padConstantTime.
+func padAD(additionalData []byte, size int) []byte {
+	if len(additionalData) > size {
+		panic("gcm: additional data longer than padded size")
+	}
+
+	padded := make([]byte, size+1)
+
+	// Pad a one-byte buffer for empty additionalData so there is always
+	// something to index; its byte is masked out.
+	src := additionalData
+	if len(src) == 0 {
+		src = []byte{0}
+	}
+
+	n := len(additionalData)
+	for i := range padded {
+		keep := 1 - subtle.ConstantTimeLessOrEq(n, i)
+		j := subtle.ConstantTimeSelect(keep, i, 0)
+		marker := subtle.ConstantTimeEq(int32(i), int32(n))
+		padded[i] = src[j]&byte(-keep) | 0x80&byte(-marker)
+	}
+	return padded
+}
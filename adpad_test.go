@@ -0,0 +1,65 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPaddedAD(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	const size = 64
+
+	short := newGCMEncrypterPaddedAD(block, nonce, []byte("id=1"), size)
+	long := newGCMEncrypterPaddedAD(block, nonce, []byte("id=1; role=administrator; region=eu-west"), size)
+	empty := newGCMEncrypterPaddedAD(block, nonce, nil, size)
+
+	// Every additional data costs the same number of GHASH multiplications
+	// and reports the same length.
+	assert.Equal(t, short.ghashBlocks, long.ghashBlocks)
+	assert.Equal(t, short.ghashBlocks, empty.ghashBlocks)
+	shortAD, _ := short.Stats()
+	longAD, _ := long.Stats()
+	assert.Equal(t, uint64(size+1), shortAD)
+	assert.Equal(t, shortAD, longAD)
+
+	ciphertext := short.Encrypt(nil, decryptedPacket)
+	tag := short.Tag()
+
+	padded := make([]byte, size+1)
+	copy(padded, "id=1\x80")
+	reference := newGCMEncrypter(block, nonce, padded)
+	assert.Equal(t, reference.Encrypt(nil, decryptedPacket), ciphertext)
+	assert.Equal(t, reference.Tag(), tag)
+
+	d := newGCMDecrypterPaddedAD(block, nonce, []byte("id=1"), size)
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, d.VerifyArray(tag))
+
+	d = newGCMDecrypterPaddedAD(block, nonce, []byte("id=2"), size)
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrOpen, d.VerifyArray(tag))
+
+	assert.Panics(t, func() { newGCMEncrypterPaddedAD(block, nonce, make([]byte, size+1), size) })
+}
+
+func TestPaddedADTrailingZeros(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	tags := map[[gcmTagSize]byte]string{}
+	for _, ad := range []string{"", "\x00", "ab", "ab\x00", "ab\x00\x00", "ab\x80"} {
+		e := newGCMEncrypterPaddedAD(block, nonce, []byte(ad), 16)
+		tag := e.Tag()
+		assert.NotContains(t, tags, tag, "%q and %q", ad, tags[tag])
+		tags[tag] = ad
+	}
+
+	assert.Equal(t, []byte{0x80, 0, 0}, padAD(nil, 2))
+	assert.Equal(t, []byte{'a', 'b', 0x80}, padAD([]byte("ab"), 2))
+}
@@ -0,0 +1,120 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"errors"
+)
+
+// gcmMinimumTagSize is the shortest tag the AEAD adapter accepts, as in
+// crypto/cipher.
+const gcmMinimumTagSize = 12
+
+var errCiphertextTooShort = errors.New("gcm: ciphertext shorter than tag")
+
+// gcmAEAD adapts the streaming encrypter and decrypter to cipher.AEAD for
+// one-shot use. It takes 16-byte nonces and appends the tag, optionally
+// truncated, to the ciphertext.
+//
+// Seal and Open follow the aliasing rules of crypto/cipher: the output may
+// overlap the input only exactly, and must not overlap the additional data
+// at all. Like crypto/cipher, Open returns nil for an empty plaintext opened
+// with a nil dst.
+type gcmAEAD struct {
+	base    *gcm
+	tagSize int
+}
+
+var _ cipher.AEAD = (*gcmAEAD)(nil)
+
+func newGCMAEAD(cipher cipher.Block) *gcmAEAD {
+	return newGCMAEADWithTagSize(cipher, gcmTagSize)
+}
+
+// newGCMAEADWithTagSize is like newGCMAEAD but truncates tags to tagSize
+// bytes, which must be between 12 and 16.
+func newGCMAEADWithTagSize(cipher cipher.Block, tagSize int) *gcmAEAD {
+	if tagSize < gcmMinimumTagSize || tagSize > gcmTagSize {
+		panic("gcm: incorrect tag size given to GCM")
+	}
+
+	return &gcmAEAD{
+		base:    newGCM(cipher),
+		tagSize: tagSize,
+	}
+}
+
+func (a *gcmAEAD) NonceSize() int {
+	return gcmNonceSize
+}
+
+func (a *gcmAEAD) Overhead() int {
+	return a.tagSize
+}
+
+// CiphertextLen returns the length of the output of Seal for a plaintext of
+// plaintextLen bytes.
+func (a *gcmAEAD) CiphertextLen(plaintextLen int) int {
+	return plaintextLen + a.Overhead()
+}
+
+// PlaintextLen returns the length of the output of Open for a ciphertext of
+// ciphertextLen bytes, or an error if it is too short to hold a tag.
+func (a *gcmAEAD) PlaintextLen(ciphertextLen int) (int, error) {
+	if ciphertextLen < a.Overhead() {
+		return 0, errCiphertextTooShort
+	}
+	return ciphertextLen - a.Overhead(), nil
+}
+
+func (a *gcmAEAD) Seal(dst, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmNonceSize {
+		panic(errNonceSize)
+	}
+
+	ret, out := sliceForAppend(dst, len(plaintext)+a.tagSize)
+	if inexactOverlap(out, plaintext) {
+		panic("gcm: invalid buffer overlap of output and input")
+	}
+	if anyOverlap(out, additionalData) {
+		panic("gcm: invalid buffer overlap of output and additional data")
+	}
+
+	g := a.base.fork().newEncrypter(nonce, additionalData)
+	g.Encrypt(out[:0], plaintext)
+
+	tag := g.Tag()
+	copy(out[len(plaintext):], tag[:a.tagSize])
+	return ret
+}
+
+func (a *gcmAEAD) Open(dst, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmNonceSize {
+		panic(errNonceSize)
+	}
+	if len(ciphertext) < a.tagSize {
+		return nil, ErrOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-a.tagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-a.tagSize]
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if inexactOverlap(out, ciphertext) {
+		panic("gcm: invalid buffer overlap of output and input")
+	}
+	if anyOverlap(out, additionalData) {
+		panic("gcm: invalid buffer overlap of output and additional data")
+	}
+
+	g := a.base.fork().newDecrypter(nonce, additionalData)
+	g.Decrypt(out[:0], ciphertext)
+
+	expected := g.Tag()
+	if subtle.ConstantTimeCompare(expected[:a.tagSize], tag) != 1 {
+		clear(out)
+		return nil, ErrOpen
+	}
+
+	return ret, nil
+}
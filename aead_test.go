@@ -0,0 +1,129 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAEADMatchesStdlib(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, tagSize := range []int{12, 14, 16} {
+		std, err := cipher.NewGCMWithTagSize(block, tagSize)
+		assert.Nil(t, err)
+		std16, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+		assert.Nil(t, err)
+
+		a := newGCMAEADWithTagSize(block, tagSize)
+		sealed := a.Seal(nil, nonce, decryptedPacket, []byte("ad"))
+
+		// Truncated tags are prefixes of the full tag.
+		full := std16.Seal(nil, nonce, decryptedPacket, []byte("ad"))
+		assert.Equal(t, full[:len(decryptedPacket)+tagSize], sealed)
+		assert.Equal(t, tagSize, std.Overhead())
+		assert.Equal(t, std.Overhead(), a.Overhead())
+
+		opened, err := a.Open(nil, nonce, sealed, []byte("ad"))
+		assert.Nil(t, err)
+		assert.Equal(t, decryptedPacket, opened)
+
+		sealed[0] ^= 1
+		opened, err = a.Open(nil, nonce, sealed, []byte("ad"))
+		assert.Equal(t, ErrOpen, err)
+		assert.Nil(t, opened)
+	}
+
+	assert.Panics(t, func() { newGCMAEADWithTagSize(block, 11) })
+	assert.Panics(t, func() { newGCMAEADWithTagSize(block, 17) })
+}
+
+func TestAEADInPlace(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	a := newGCMAEAD(block)
+	buf := append(make([]byte, 0, len(decryptedPacket)+gcmTagSize), decryptedPacket...)
+
+	sealed := a.Seal(buf[:0], nonce, buf, nil)
+	opened, err := a.Open(sealed[:0], nonce, sealed, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, opened)
+}
+
+func TestAEADLengths(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, tagSize := range []int{12, 16} {
+		a := newGCMAEADWithTagSize(block, tagSize)
+
+		for _, n := range []int{0, 1, 100} {
+			sealed := a.Seal(nil, nonce, make([]byte, n), nil)
+			assert.Equal(t, len(sealed), a.CiphertextLen(n))
+
+			plaintextLen, err := a.PlaintextLen(len(sealed))
+			assert.Nil(t, err)
+			assert.Equal(t, n, plaintextLen)
+		}
+
+		_, err := a.PlaintextLen(tagSize - 1)
+		assert.Equal(t, errCiphertextTooShort, err)
+	}
+}
+
+func TestAEADEmpty(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	std, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+	a := newGCMAEAD(block)
+
+	for _, ad := range [][]byte{nil, {}, []byte("ad")} {
+		sealed := a.Seal(nil, nonce, nil, ad)
+		assert.Equal(t, std.Seal(nil, nonce, nil, ad), sealed)
+		assert.Len(t, sealed, a.Overhead())
+
+		opened, err := a.Open(nil, nonce, sealed, ad)
+		assert.Nil(t, err)
+		assert.Nil(t, opened)
+
+		opened, err = a.Open([]byte{}, nonce, sealed, ad)
+		assert.Nil(t, err)
+		assert.NotNil(t, opened)
+		assert.Empty(t, opened)
+
+		opened, err = a.Open([]byte("prefix"), nonce, sealed, ad)
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("prefix"), opened)
+
+		_, err = a.Open(nil, nonce, sealed[1:], ad)
+		assert.Equal(t, ErrOpen, err)
+	}
+}
+
+func TestAEADOverlap(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	a := newGCMAEAD(block)
+	buf := make([]byte, 128)
+	sealed := a.Seal(nil, nonce, make([]byte, 10), nil)
+	copy(buf, sealed)
+
+	assert.Panics(t, func() { a.Seal(buf[1:1], nonce, buf[:10], nil) })
+	assert.Panics(t, func() { a.Seal(buf[:0], nonce, nil, buf[:5]) })
+	assert.Panics(t, func() { a.Open(buf[1:1], nonce, buf[:len(sealed)], nil) })
+	assert.Panics(t, func() { a.Open(buf[:0], nonce, buf[:len(sealed)], buf[:2]) })
+
+	// Exact overlap of output and input is allowed, and additional data may
+	// follow the output.
+	opened, err := a.Open(buf[:0], nonce, buf[:len(sealed)], nil)
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 10), opened)
+	assert.NotPanics(t, func() { a.Seal(buf[:0], nonce, buf[:10], buf[64:70]) })
+}
@@ -0,0 +1,116 @@
+package uncheckedgcm
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Collision describes a nonce that appears in more than one record.
+type Collision struct {
+	Nonce   []byte
+	Indices []int
+}
+
+// AuditNonces scans records laid out as nonce||ciphertext and reports every
+// nonce that is used more than once. Under a single key, a repeated nonce
+// means the keystream was reused and both the plaintexts and the
+// authentication key are at risk.
+//
+// Records shorter than nonceLen are ignored. Collisions are returned in order
+// of the first record that used the nonce.
+func AuditNonces(records [][]byte, nonceLen int) []Collision {
+	if nonceLen < 0 {
+		panic("gcm: negative nonce length")
+	}
+
+	return collisions(len(records), func(i int) ([]byte, bool) {
+		if len(records[i]) < nonceLen {
+			return nil, false
+		}
+		return records[i][:nonceLen], true
+	})
+}
+
+// collisions groups n records by the nonce that nonceOf returns for each,
+// skipping records for which it returns false, and returns the groups with
+// more than one record in order of their first record.
+func collisions(n int, nonceOf func(i int) ([]byte, bool)) []Collision {
+	seen := make(map[string]int)
+	var groups []Collision
+
+	for i := 0; i < n; i++ {
+		nonce, ok := nonceOf(i)
+		if !ok {
+			continue
+		}
+
+		j, ok := seen[string(nonce)]
+		if !ok {
+			groups = append(groups, Collision{
+				Nonce:   append([]byte(nil), nonce...),
+				Indices: []int{i},
+			})
+			seen[string(nonce)] = len(groups) - 1
+			continue
+		}
+		groups[j].Indices = append(groups[j].Indices, i)
+	}
+
+	var repeated []Collision
+	for _, group := range groups {
+		if len(group.Indices) > 1 {
+			repeated = append(repeated, group)
+		}
+	}
+	return repeated
+}
+
+// Record is a sealed message with its nonce and tag kept separately.
+type Record struct {
+	Nonce      []byte
+	Ciphertext []byte
+	Tag        []byte
+}
+
+// ReuseError lists pairs of records, by index, that share a nonce.
+type ReuseError struct {
+	Pairs [][2]int
+}
+
+func (e *ReuseError) Error() string {
+	return fmt.Sprintf("gcm: %d pairs of records share a nonce", len(e.Pairs))
+}
+
+// DetectReuse reports records that were sealed with the same nonce. If they
+// were also sealed under the same key, XORing their ciphertexts cancels the
+// keystream and leaves the XOR of the plaintexts, and their tags leak the
+// hash subkey. It returns nil if every nonce is distinct, and a *ReuseError
+// otherwise.
+//
+// Nonces are compared directly rather than by looking for structure in the
+// XOR of ciphertexts, which cannot tell reuse apart from unrelated data.
+func DetectReuse(records []Record) error {
+	groups := collisions(len(records), func(i int) ([]byte, bool) {
+		return records[i].Nonce, true
+	})
+
+	var pairs [][2]int
+	for _, group := range groups {
+		for a, i := range group.Indices {
+			for _, j := range group.Indices[a+1:] {
+				pairs = append(pairs, [2]int{i, j})
+			}
+		}
+	}
+
+	if len(pairs) == 0 {
+		return nil
+	}
+	slices.SortFunc(pairs, func(x, y [2]int) int {
+		if x[0] != y[0] {
+			return x[0] - y[0]
+		}
+		return x[1] - y[1]
+	})
+	return &ReuseError{pairs}
+}
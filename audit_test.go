@@ -0,0 +1,82 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditNonces(t *testing.T) {
+	records := [][]byte{
+		{1, 1, 0xaa},
+		{2, 2, 0xbb},
+		{1, 1, 0xcc},
+		{3},
+		{2, 2},
+		{1, 1, 0xdd, 0xee},
+	}
+
+	collisions := AuditNonces(records, 2)
+	assert.Equal(t, []Collision{
+		{Nonce: []byte{1, 1}, Indices: []int{0, 2, 5}},
+		{Nonce: []byte{2, 2}, Indices: []int{1, 4}},
+	}, collisions)
+}
+
+func TestAuditNoncesUnique(t *testing.T) {
+	records := [][]byte{
+		append([]byte{}, nonce...),
+		append([]byte{}, key...),
+	}
+
+	assert.Empty(t, AuditNonces(records, gcmNonceSize))
+}
+
+func TestDetectReuse(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	seal := func(nonce, plaintext []byte) Record {
+		ciphertext, tag := newGCMEncrypter(block, nonce, nil).SealDetached(plaintext)
+		return Record{nonce, ciphertext, tag[:]}
+	}
+
+	other := append([]byte{}, nonce...)
+	other[0] ^= 1
+
+	records := []Record{
+		seal(nonce, []byte("attack at dawn")),
+		seal(other, []byte("attack at dusk")),
+		seal(nonce, []byte("retreat at ten")),
+	}
+	assert.Nil(t, DetectReuse(records[:2]))
+
+	err = DetectReuse(records)
+	var reuseErr *ReuseError
+	assert.ErrorAs(t, err, &reuseErr)
+	assert.Equal(t, [][2]int{{0, 2}}, reuseErr.Pairs)
+
+	// The keystream cancels, exposing the XOR of the plaintexts.
+	xored := make([]byte, len(records[0].Ciphertext))
+	subtle.XORBytes(xored, records[0].Ciphertext, records[2].Ciphertext)
+
+	want := make([]byte, len(xored))
+	subtle.XORBytes(want, []byte("attack at dawn"), []byte("retreat at ten"))
+	assert.Equal(t, want, xored)
+}
+
+func TestDetectReusePairs(t *testing.T) {
+	a, b := []byte{1}, []byte{2}
+	records := []Record{{Nonce: a}, {Nonce: b}, {Nonce: a}, {Nonce: b}, {Nonce: a}}
+
+	err := DetectReuse(records)
+	var reuseErr *ReuseError
+	assert.ErrorAs(t, err, &reuseErr)
+	assert.Equal(t, [][2]int{{0, 2}, {0, 4}, {1, 3}, {2, 4}}, reuseErr.Pairs)
+}
+
+func TestAuditNoncesNegativeLength(t *testing.T) {
+	assert.PanicsWithValue(t, "gcm: negative nonce length", func() { AuditNonces([][]byte{{1}}, -1) })
+}
@@ -0,0 +1,60 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// Authenticator computes a GCM tag over ciphertext that was produced
+// elsewhere, for example by a separate AES-CTR implementation. It performs
+// no encryption itself.
+type Authenticator struct {
+	gcm              *gcm
+	additionalDataNb uint64
+	ciphertextNb     uint64
+}
+
+// NewAuthenticator returns an Authenticator for the message with the given
+// nonce and additional data. The ciphertext it is fed must have been produced
+// with the GCM keystream for nonce, starting at the counter after J0.
+func NewAuthenticator(cipher cipher.Block, nonce, additionalData []byte) *Authenticator {
+	if err := Validate(nonce, additionalData); err != nil {
+		panic(err)
+	}
+
+	g := newGCM(cipher)
+	g.start(nonce, additionalData)
+
+	return &Authenticator{
+		gcm:              g,
+		additionalDataNb: uint64(len(additionalData)),
+	}
+}
+
+// Update adds ciphertext to the message. It may be called any number of
+// times; the result does not depend on how the ciphertext is split.
+func (a *Authenticator) Update(ciphertext []byte) {
+	if a.gcm.finalized {
+		panic(ErrFinalized)
+	}
+
+	a.gcm.endAD()
+	a.gcm.updateStream(ciphertext)
+	a.ciphertextNb += uint64(len(ciphertext))
+}
+
+// Tag returns the GCM tag for the ciphertext added so far. Like the
+// encrypter's Tag, it finalizes the Authenticator: further calls return the
+// same tag and Update panics with ErrFinalized.
+func (a *Authenticator) Tag() [gcmTagSize]byte {
+	g := a.gcm
+	if !g.finalized {
+		g.flush()
+		g.finalize(g.tag(&g.ghash, a.additionalDataNb, a.ciphertextNb))
+	}
+	return g.finalTag
+}
+
+// Sum appends the tag to b and returns the result, like hash.Hash.Sum. It
+// finalizes the Authenticator in the same way as Tag.
+func (a *Authenticator) Sum(b []byte) []byte {
+	tag := a.Tag()
+	return append(b, tag[:]...)
+}
@@ -0,0 +1,36 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticator(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	ad := []byte("external ctr")
+	plaintext := make([]byte, 75)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	e := newGCMEncrypter(block, nonce, ad)
+	want := e.Encrypt(nil, plaintext)
+	wantTag := e.Tag()
+
+	// Encrypt with the standard library's CTR mode from the same initial
+	// counter, so the authenticator sees only foreign ciphertext.
+	iv := newGCMEncrypter(block, nonce, nil).InitialCounter()
+	ciphertext := make([]byte, len(plaintext))
+	cipher.NewCTR(block, iv[:]).XORKeyStream(ciphertext, plaintext)
+	assert.Equal(t, want, ciphertext)
+
+	a := NewAuthenticator(block, nonce, ad)
+	a.Update(ciphertext[:10])
+	a.Update(ciphertext[10:])
+	assert.Equal(t, wantTag, a.Tag())
+}
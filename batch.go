@@ -0,0 +1,51 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// BatchEncrypt encrypts each plaintext under the nonce at the same index,
+// spreading the work across GOMAXPROCS goroutines. The hash subkey is
+// derived once and shared by every message. block must be safe for
+// concurrent use, as the crypto/aes implementation is.
+func BatchEncrypt(block cipher.Block, nonces, plaintexts [][]byte) ([][]byte, [][gcmTagSize]byte, error) {
+	if len(nonces) != len(plaintexts) {
+		return nil, nil, errors.New("gcm: nonce and plaintext counts differ")
+	}
+	for i, nonce := range nonces {
+		if err := Validate(nonce, nil); err != nil {
+			return nil, nil, fmt.Errorf("gcm: message %d: %w", i, err)
+		}
+	}
+
+	base := newGCM(block)
+	ciphertexts := make([][]byte, len(plaintexts))
+	tags := make([][gcmTagSize]byte, len(plaintexts))
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+
+	for range min(runtime.GOMAXPROCS(0), len(plaintexts)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				g := base.fork().newEncrypter(nonces[i], nil)
+				ciphertexts[i] = g.Encrypt(nil, plaintexts[i])
+				tags[i] = g.Tag()
+			}
+		}()
+	}
+
+	for i := range plaintexts {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return ciphertexts, tags, nil
+}
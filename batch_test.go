@@ -0,0 +1,78 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchInputs(count, size int) (nonces, plaintexts [][]byte) {
+	for i := range count {
+		n := append([]byte{}, nonce...)
+		binary.BigEndian.PutUint32(n, uint32(i))
+		nonces = append(nonces, n)
+
+		plaintext := make([]byte, size+i%17)
+		for j := range plaintext {
+			plaintext[j] = byte(i + j)
+		}
+		plaintexts = append(plaintexts, plaintext)
+	}
+	return
+}
+
+func TestBatchEncrypt(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	nonces, plaintexts := batchInputs(64, 100)
+
+	ciphertexts, tags, err := BatchEncrypt(block, nonces, plaintexts)
+	assert.Nil(t, err)
+
+	for i := range plaintexts {
+		g := newGCMEncrypter(block, nonces[i], nil)
+		assert.Equal(t, g.Encrypt(nil, plaintexts[i]), ciphertexts[i])
+		assert.Equal(t, g.Tag(), tags[i])
+	}
+}
+
+func TestBatchEncryptInvalid(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	nonces, plaintexts := batchInputs(4, 16)
+
+	_, _, err = BatchEncrypt(block, nonces[:3], plaintexts)
+	assert.NotNil(t, err)
+
+	nonces[2] = nil
+	_, _, err = BatchEncrypt(block, nonces, plaintexts)
+	assert.ErrorIs(t, err, errNonceSize)
+}
+
+func BenchmarkBatchEncrypt(b *testing.B) {
+	block, _ := aes.NewCipher(key)
+	nonces, plaintexts := batchInputs(256, 4096)
+
+	b.SetBytes(256 * 4096)
+	for i := 0; i < b.N; i++ {
+		BatchEncrypt(block, nonces, plaintexts)
+	}
+}
+
+func BenchmarkBatchEncryptSerial(b *testing.B) {
+	block, _ := aes.NewCipher(key)
+	nonces, plaintexts := batchInputs(256, 4096)
+
+	b.SetBytes(256 * 4096)
+	for i := 0; i < b.N; i++ {
+		for j := range plaintexts {
+			g := newGCMEncrypter(block, nonces[j], nil)
+			g.Encrypt(nil, plaintexts[j])
+			g.Tag()
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package uncheckedgcm
+
+// maxCounterBatch is the largest number of counter blocks counterCrypt
+// encrypts before XORing them into the data. It is also the default.
+const maxCounterBatch = 8
+
+// SetCounterBatch sets how many counter blocks are encrypted at a time
+// before being XORed into the data, for tuning to a CPU's caches. n must be
+// between 1 and 8, or 0 to restore the default. The output does not depend
+// on n.
+func (g *gcm) SetCounterBatch(n int) {
+	if n < 0 || n > maxCounterBatch {
+		panic("gcm: counter batch size out of range")
+	}
+	g.counterBatch = n
+}
@@ -0,0 +1,61 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterBatch(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 301)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	want := newGCMEncrypter(block, nonce, nil)
+	wantCiphertext := want.Encrypt(nil, plaintext)
+	wantTag := want.Tag()
+
+	for n := 0; n <= maxCounterBatch; n++ {
+		e := newGCMEncrypter(block, nonce, nil)
+		e.SetCounterBatch(n)
+
+		var ciphertext []byte
+		for _, chunk := range []int{1, 40, 7, 130, 123} {
+			ciphertext = e.Encrypt(ciphertext, plaintext[len(ciphertext):len(ciphertext)+chunk])
+		}
+
+		assert.Equal(t, wantCiphertext, ciphertext, "batch %d", n)
+		assert.Equal(t, want.FinalCounter(), e.FinalCounter(), "batch %d", n)
+		assert.Equal(t, wantTag, e.Tag(), "batch %d", n)
+	}
+
+	assert.Panics(t, func() { newGCMEncrypter(block, nonce, nil).SetCounterBatch(-1) })
+	assert.Panics(t, func() { newGCMEncrypter(block, nonce, nil).SetCounterBatch(maxCounterBatch + 1) })
+}
+
+func BenchmarkCounterBatch(b *testing.B) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	buf := make([]byte, 16*1024)
+	for _, n := range []int{1, 2, 4, 8} {
+		b.Run(fmt.Sprint(n), func(b *testing.B) {
+			e := newGCMEncrypter(block, nonce, nil)
+			e.SetCounterBatch(n)
+
+			b.SetBytes(int64(len(buf)))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				e.counterCrypt(buf, buf, &e.counter)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// BufferedDecrypter decrypts into a buffer it owns and wipes that buffer if
+// verification fails, so unverified plaintext cannot outlive a bad tag. It
+// exposes only the Decrypter methods that keep that promise; other ways of
+// decrypting would put plaintext outside the buffer.
+type BufferedDecrypter struct {
+	g   *Decrypter
+	buf []byte
+}
+
+func newBufferedDecrypter(cipher cipher.Block, nonce, additionalData []byte) *BufferedDecrypter {
+	return &BufferedDecrypter{g: newGCMDecrypter(cipher, nonce, additionalData)}
+}
+
+// Decrypt decrypts ciphertext into the internal buffer and returns the
+// plaintext so far. The returned slice aliases the buffer: it is zeroed if
+// Verify fails and is only valid until the next call to Decrypt. If the
+// underlying Decrypt fails, for example with ErrFinalized after Verify, the
+// error is returned and the buffer, including any slice returned earlier, is
+// left as it was.
+func (b *BufferedDecrypter) Decrypt(ciphertext []byte) ([]byte, error) {
+	// Fail before growing, which would wipe the buffer the caller holds.
+	if b.g.discarded {
+		return nil, ErrDiscarded
+	}
+	if b.g.finalized {
+		return nil, ErrFinalized
+	}
+	if err := b.g.checkKeystream(len(ciphertext)); err != nil {
+		return nil, err
+	}
+
+	if cap(b.buf)-len(b.buf) < len(ciphertext) {
+		grown := make([]byte, len(b.buf), 2*cap(b.buf)+len(ciphertext))
+		copy(grown, b.buf)
+		clear(b.buf)
+		b.buf = grown
+	}
+
+	buf, err := b.g.Decrypt(b.buf, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	b.buf = buf
+	return b.buf, nil
+}
+
+// Verify checks tag against the ciphertext decrypted so far. On failure the
+// buffered plaintext is zeroed before ErrOpen is returned.
+func (b *BufferedDecrypter) Verify(tag []byte) error {
+	if err := b.g.Verify(tag); err != nil {
+		clear(b.buf)
+		return err
+	}
+	return nil
+}
+
+// VerifyArray is Verify for a fixed-size tag.
+func (b *BufferedDecrypter) VerifyArray(tag [gcmTagSize]byte) error {
+	return b.Verify(tag[:])
+}
+
+// VerifyExpected is Verify with the tag given to SetExpectedTag, and wipes
+// the buffer on failure in the same way.
+func (b *BufferedDecrypter) VerifyExpected() error {
+	if err := b.g.VerifyExpected(); err != nil {
+		clear(b.buf)
+		return err
+	}
+	return nil
+}
+
+// VerifyAny is Verify accepting a full or 12-byte truncated tag, and wipes
+// the buffer on failure in the same way.
+func (b *BufferedDecrypter) VerifyAny(tag []byte) error {
+	if err := b.g.VerifyAny(tag); err != nil {
+		clear(b.buf)
+		return err
+	}
+	return nil
+}
+
+// SetExpectedTag records the tag for VerifyExpected, as on Decrypter.
+func (b *BufferedDecrypter) SetExpectedTag(tag []byte) {
+	b.g.SetExpectedTag(tag)
+}
+
+// Tag returns the tag for the ciphertext decrypted so far and finalizes the
+// decrypter, as on Decrypter.
+func (b *BufferedDecrypter) Tag() [gcmTagSize]byte {
+	return b.g.Tag()
+}
@@ -0,0 +1,144 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBufferedDecrypter(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 100)
+	for i := range plaintext {
+		plaintext[i] = byte(i + 1)
+	}
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	b := newBufferedDecrypter(block, nonce, nil)
+	_, err = b.Decrypt(ciphertext[:30])
+	assert.Nil(t, err)
+	got, err := b.Decrypt(ciphertext[30:])
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, got)
+	assert.Nil(t, b.VerifyArray(tag))
+	assert.Equal(t, plaintext, got)
+}
+
+func TestBufferedDecrypterWipesOnFailure(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 100)
+	for i := range plaintext {
+		plaintext[i] = byte(i + 1)
+	}
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+	tag[0] ^= 1
+
+	b := newBufferedDecrypter(block, nonce, nil)
+	first, err := b.Decrypt(ciphertext[:10])
+	assert.Nil(t, err)
+	got, err := b.Decrypt(ciphertext[10:])
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, got)
+
+	assert.ErrorIs(t, b.Verify(tag[:]), ErrOpen)
+	assert.Equal(t, make([]byte, len(plaintext)), got)
+
+	// The smaller buffer was abandoned when the buffer grew; it must not
+	// have kept a copy of the plaintext either.
+	assert.Equal(t, make([]byte, len(first)), first)
+}
+
+func TestBufferedDecrypterDecryptAfterVerify(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := []byte("buffered plaintext")
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	b := newBufferedDecrypter(block, nonce, nil)
+	got, err := b.Decrypt(ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, b.VerifyArray(tag))
+
+	// The error is reported and the verified plaintext is left alone.
+	more, err := b.Decrypt(ciphertext)
+	assert.Equal(t, ErrFinalized, err)
+	assert.Nil(t, more)
+	assert.Equal(t, plaintext, got)
+	assert.Equal(t, plaintext, b.buf)
+}
+
+func TestBufferedDecrypterVerifyExpectedWipes(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := []byte("buffered plaintext")
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	b := newBufferedDecrypter(block, nonce, nil)
+	b.SetExpectedTag(tag[:])
+	got, err := b.Decrypt(ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, b.VerifyExpected())
+	assert.Equal(t, plaintext, got)
+
+	tag[0] ^= 1
+	b = newBufferedDecrypter(block, nonce, nil)
+	b.SetExpectedTag(tag[:])
+	got, err = b.Decrypt(ciphertext)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, b.VerifyExpected(), ErrOpen)
+	assert.Equal(t, make([]byte, len(plaintext)), got)
+}
+
+func TestBufferedDecrypterVerifyAnyWipes(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := []byte("buffered plaintext")
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	b := newBufferedDecrypter(block, nonce, nil)
+	got, err := b.Decrypt(ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, b.VerifyAny(tag[:gcmMinimumTagSize]))
+	assert.Equal(t, plaintext, got)
+
+	tag[0] ^= 1
+	b = newBufferedDecrypter(block, nonce, nil)
+	got, err = b.Decrypt(ciphertext)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, b.VerifyAny(tag[:gcmMinimumTagSize]), ErrOpen)
+	assert.Equal(t, make([]byte, len(plaintext)), got)
+}
+
+func TestBufferedDecrypterMethods(t *testing.T) {
+	// Every exported method must either keep plaintext in the buffer or
+	// wipe it on failure; new Decrypter methods are not inherited.
+	var methods []string
+	typ := reflect.TypeOf(&BufferedDecrypter{})
+	for i := 0; i < typ.NumMethod(); i++ {
+		methods = append(methods, typ.Method(i).Name)
+	}
+	assert.Equal(t, []string{
+		"Decrypt", "SetExpectedTag", "Tag", "Verify", "VerifyAny", "VerifyArray", "VerifyExpected",
+	}, methods)
+}
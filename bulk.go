@@ -0,0 +1,36 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// BulkBlock is a cipher.Block that can encrypt many blocks in one call, such
+// as a block cipher behind a remote key management service. When the cipher
+// passed to a constructor implements it, all the keystream for each Encrypt
+// or Decrypt call is requested at once instead of block by block.
+type BulkBlock interface {
+	cipher.Block
+
+	// EncryptBlocks encrypts each 16-byte block of src into the
+	// corresponding block of dst. len(src) is a multiple of 16 and dst and
+	// src do not overlap.
+	EncryptBlocks(dst, src []byte)
+}
+
+// keystream fills dst, a whole number of blocks, with keystream from
+// successive values of counter.
+func (g *gcm) keystream(dst []byte, counter *[gcmBlockSize]byte) {
+	bulk, ok := g.cipher.(BulkBlock)
+	if !ok {
+		for i := 0; i < len(dst); i += gcmBlockSize {
+			g.cipher.Encrypt(dst[i:i+gcmBlockSize], counter[:])
+			gcmInc32(counter)
+		}
+		return
+	}
+
+	counters := make([]byte, len(dst))
+	for i := 0; i < len(counters); i += gcmBlockSize {
+		copy(counters[i:], counter[:])
+		gcmInc32(counter)
+	}
+	bulk.EncryptBlocks(dst, counters)
+}
@@ -0,0 +1,64 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// bulkBlock counts single-block and bulk calls, like a remote service
+// billing per request.
+type bulkBlock struct {
+	cipher.Block
+	calls, bulkCalls int
+}
+
+func (b *bulkBlock) Encrypt(dst, src []byte) {
+	b.calls++
+	b.Block.Encrypt(dst, src)
+}
+
+func (b *bulkBlock) EncryptBlocks(dst, src []byte) {
+	b.bulkCalls++
+	for i := 0; i < len(src); i += gcmBlockSize {
+		b.Block.Encrypt(dst[i:i+gcmBlockSize], src[i:i+gcmBlockSize])
+	}
+}
+
+func TestBulkBlock(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 4096+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	want := newGCMEncrypter(aesBlock, nonce, nil)
+	wantCiphertext := want.Encrypt(nil, plaintext)
+	wantTag := want.Tag()
+
+	block := &bulkBlock{Block: aesBlock}
+	e := newGCMEncrypter(block, nonce, nil)
+	setupCalls := block.calls
+
+	ciphertext := e.Encrypt(nil, plaintext[:3000])
+	ciphertext = e.Encrypt(ciphertext, plaintext[3000:])
+	assert.Equal(t, wantCiphertext, ciphertext)
+	assert.Equal(t, wantTag, e.Tag())
+	assert.Equal(t, want.FinalCounter(), e.FinalCounter())
+
+	// Two Encrypt calls, two requests, and no single-block calls beyond
+	// deriving H, J0 and the tag mask.
+	assert.Equal(t, 2, block.bulkCalls)
+	assert.Equal(t, setupCalls, block.calls)
+
+	e = newGCMEncrypter(block, nonce, nil)
+	block.bulkCalls = 0
+	e.Reserve(len(plaintext))
+	assert.Equal(t, 1, block.bulkCalls)
+	assert.Equal(t, wantCiphertext, e.Encrypt(nil, plaintext))
+	assert.Equal(t, 1, block.bulkCalls)
+}
@@ -0,0 +1,31 @@
+package uncheckedgcm
+
+import "encoding/binary"
+
+// CCMFrameAdditionalData returns additionalData prefixed with its length
+// encoded as in CCM (RFC 3610, Section 2.2): two bytes for lengths below
+// 2^16-2^8, 0xfffe and four bytes below 2^32, and 0xffff and eight bytes
+// otherwise. Empty additional data has no prefix.
+//
+// Passing the result to a GCM constructor hashes the additional data with
+// CCM's framing, for wire formats that are migrating from CCM. The result is
+// not CCM, and does not interoperate with standard GCM either.
+func CCMFrameAdditionalData(additionalData []byte) []byte {
+	n := uint64(len(additionalData))
+
+	var framed []byte
+	switch {
+	case n == 0:
+		return nil
+	case n < 1<<16-1<<8:
+		framed = binary.BigEndian.AppendUint16(framed, uint16(n))
+	case n < 1<<32:
+		framed = append(framed, 0xff, 0xfe)
+		framed = binary.BigEndian.AppendUint32(framed, uint32(n))
+	default:
+		framed = append(framed, 0xff, 0xff)
+		framed = binary.BigEndian.AppendUint64(framed, n)
+	}
+
+	return append(framed, additionalData...)
+}
@@ -0,0 +1,58 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCCMFrameAdditionalData(t *testing.T) {
+	assert.Nil(t, CCMFrameAdditionalData(nil))
+
+	framed := CCMFrameAdditionalData(make([]byte, 1<<16-1<<8-1))
+	assert.Equal(t, []byte{0xfe, 0xff}, framed[:2])
+	assert.Len(t, framed, 2+1<<16-1<<8-1)
+
+	framed = CCMFrameAdditionalData(make([]byte, 1<<16-1<<8))
+	assert.Equal(t, []byte{0xff, 0xfe, 0x00, 0x00, 0xff, 0x00}, framed[:6])
+	assert.Len(t, framed, 6+1<<16-1<<8)
+}
+
+func TestCCMFrameAdditionalDataVector(t *testing.T) {
+	// RFC 3610, Packet Vector #1: the first CBC-MAC input after B_0 is the
+	// framed header, and the RFC lists AES(B_0) XOR B_1 as "After xor".
+	block, err := aes.NewCipher(decodeHex(t, "c0c1c2c3c4c5c6c7c8c9cacbcccdcecf"))
+	assert.Nil(t, err)
+
+	b1 := make([]byte, gcmBlockSize)
+	copy(b1, CCMFrameAdditionalData(decodeHex(t, "0001020304050607")))
+
+	x := make([]byte, gcmBlockSize)
+	block.Encrypt(x, decodeHex(t, "5900000003020100a0a1a2a3a4a50017"))
+	for i := range x {
+		x[i] ^= b1[i]
+	}
+	assert.Equal(t, "eb955546710a51ae25190a2dfe4b90d6", hex.EncodeToString(x))
+}
+
+func TestCCMFramedGCM(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+
+	ad := []byte("ccm header")
+	framed := CCMFrameAdditionalData(ad)
+
+	e := newGCMEncrypter(block, nonce, framed)
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	// The framing only changes the additional data GHASH sees.
+	assert.Equal(t, aead.Seal(nil, nonce, decryptedPacket, framed), append(ciphertext, tag[:]...))
+	assert.NotEqual(t, aead.Seal(nil, nonce, decryptedPacket, ad), append(ciphertext, tag[:]...))
+}
@@ -0,0 +1,136 @@
+package uncheckedgcm
+
+import "encoding/binary"
+
+// Checkpoint is the state of a message at a block boundary, enough to carry
+// on from that point after a crash without processing the earlier data
+// again.
+//
+// GHASH is an intermediate hash state. Together with the ciphertext it
+// covers, it reveals the hash subkey, which allows forgeries under the key,
+// so checkpoints must be stored as carefully as the key itself.
+type Checkpoint struct {
+	GHASH                [gcmBlockSize]byte
+	Counter              [gcmBlockSize]byte
+	AdditionalDataLength uint64
+	DataLength           uint64
+}
+
+// setCheckpoints arranges for f to be called every blocks blocks of data
+// with the state at that boundary. Encrypt and Decrypt split their input at
+// checkpoint boundaries, so a checkpoint is emitted even in the middle of a
+// call. blocks = 0 turns checkpoints off.
+func (g *gcm) setCheckpoints(blocks int, f func(Checkpoint)) {
+	if g.deferAD || g.interleaved {
+		panic("gcm: checkpoints need the additional data hashed up front")
+	}
+	if blocks < 0 {
+		panic("gcm: negative checkpoint interval")
+	}
+
+	if blocks == 0 || f == nil {
+		g.checkpointEvery = 0
+		g.onCheckpoint = nil
+		return
+	}
+	g.checkpointEvery = uint64(blocks) * gcmBlockSize
+	g.onCheckpoint = f
+}
+
+// SetCheckpoints calls f every blocks blocks of plaintext with the state at
+// that boundary. Pass it to Resume on a new encrypter with the same cipher,
+// nonce and options to carry on from there. It is not available on lazy or
+// interleaved encrypters.
+func (g *Encrypter) SetCheckpoints(blocks int, f func(Checkpoint)) {
+	g.setCheckpoints(blocks, f)
+}
+
+// SetCheckpoints calls f every blocks blocks of ciphertext with the state
+// at that boundary, for Decrypter.Resume.
+func (g *Decrypter) SetCheckpoints(blocks int, f func(Checkpoint)) {
+	g.setCheckpoints(blocks, f)
+}
+
+// untilCheckpoint returns how many of the next n bytes can be processed
+// before the next checkpoint boundary, given dataNb bytes so far.
+func (g *gcm) untilCheckpoint(dataNb uint64, n int) int {
+	left := g.checkpointEvery - dataNb%g.checkpointEvery
+	if uint64(n) < left {
+		return n
+	}
+	return int(left)
+}
+
+// checkpoint emits a checkpoint if dataNb is on a boundary. The boundary is
+// block-aligned, so no GHASH tail is pending there.
+func (g *gcm) checkpoint(additionalDataNb, dataNb uint64) {
+	if dataNb == 0 || dataNb%g.checkpointEvery != 0 {
+		return
+	}
+
+	cp := Checkpoint{
+		Counter:              g.initialCounter,
+		AdditionalDataLength: additionalDataNb,
+		DataLength:           dataNb,
+	}
+	binary.BigEndian.PutUint64(cp.GHASH[:8], g.ghash.low)
+	binary.BigEndian.PutUint64(cp.GHASH[8:], g.ghash.high)
+
+	// Reserve may have generated keystream past the boundary, so derive
+	// the counter from the data length rather than reading g.counter.
+	ctr := binary.BigEndian.Uint32(cp.Counter[gcmBlockSize-4:]) + uint32(dataNb/gcmBlockSize)
+	binary.BigEndian.PutUint32(cp.Counter[gcmBlockSize-4:], ctr)
+
+	g.onCheckpoint(cp)
+}
+
+// resume restores cp on a gcm that has been started for the message's nonce
+// but has processed no data.
+func (g *gcm) resume(cp Checkpoint) {
+	g.mustBeLive()
+	if g.finalized {
+		panic(ErrFinalized)
+	}
+	if g.deferAD || g.interleaved {
+		panic("gcm: checkpoints need the additional data hashed up front")
+	}
+	if cp.DataLength%gcmBlockSize != 0 {
+		panic("gcm: checkpoint not on a block boundary")
+	}
+
+	g.ghash = gcmFieldElement{
+		binary.BigEndian.Uint64(cp.GHASH[:8]),
+		binary.BigEndian.Uint64(cp.GHASH[8:]),
+	}
+	g.ghashTailNb = 0
+	g.ghashBlocks = (cp.AdditionalDataLength+gcmBlockSize-1)/gcmBlockSize + cp.DataLength/gcmBlockSize
+	g.counter = cp.Counter
+	g.extraMask = nil
+	g.adDone = true
+}
+
+// Resume continues the message described by cp. g must be newly created
+// with the same cipher, nonce and options, such as MtE or SetLengthBlock, as
+// the encrypter that emitted cp; the additional data it was given is
+// replaced by cp, so it may be nil. Resume panics if g has encrypted any
+// data.
+func (g *Encrypter) Resume(cp Checkpoint) {
+	if g.plaintextNb != 0 {
+		panic("gcm: resume after data was encrypted")
+	}
+
+	g.resume(cp)
+	g.plaintextNb = cp.DataLength
+	g.additionalDataNb = cp.AdditionalDataLength
+}
+
+// Resume is the decrypting counterpart of Encrypter.Resume.
+func (g *Decrypter) Resume(cp Checkpoint) {
+	if g.ciphertextNb != 0 {
+		panic("gcm: resume after data was decrypted")
+	}
+
+	g.resume(cp)
+	g.ciphertextNb = cp.DataLength
+	g.additionalDataNb = cp.AdditionalDataLength
+}
@@ -0,0 +1,175 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 1000)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 3)
+	}
+	ad := []byte("additional data")
+
+	var checkpoints []Checkpoint
+	e := newGCMEncrypter(block, nonce, ad)
+	e.SetCheckpoints(4, func(cp Checkpoint) { checkpoints = append(checkpoints, cp) })
+
+	// Chunks that straddle checkpoint boundaries, with keystream reserved
+	// past one of them.
+	var ciphertext []byte
+	for i := 0; i < len(plaintext); i += 77 {
+		if i == 154 {
+			e.Reserve(100)
+		}
+		ciphertext = e.Encrypt(ciphertext, plaintext[i:min(i+77, len(plaintext))])
+	}
+	tag := e.Tag()
+
+	assert.Len(t, checkpoints, len(plaintext)/64)
+	for i, cp := range checkpoints {
+		assert.Equal(t, uint64((i+1)*64), cp.DataLength)
+		assert.Equal(t, uint64(len(ad)), cp.AdditionalDataLength)
+	}
+
+	for _, cp := range checkpoints {
+		// Resuming the encrypter reproduces the rest of the message.
+		r := newGCMEncrypter(block, nonce, nil)
+		r.Resume(cp)
+		rest := r.Encrypt(nil, plaintext[cp.DataLength:])
+		assert.Equal(t, ciphertext[cp.DataLength:], rest)
+		assert.Equal(t, tag, r.Tag())
+
+		// And so does resuming a decrypter.
+		d := newGCMDecrypter(block, nonce, nil)
+		d.Resume(cp)
+		decrypted, err := d.Decrypt(nil, ciphertext[cp.DataLength:])
+		assert.Nil(t, err)
+		assert.Equal(t, plaintext[cp.DataLength:], decrypted)
+		assert.Nil(t, d.VerifyArray(tag))
+	}
+}
+
+func TestCheckpointResumeOptions(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var h [gcmBlockSize]byte
+	copy(h[:], "not the zero blk")
+	swapped := func(additionalDataBits, dataBits uint64) [gcmBlockSize]byte {
+		return StandardLengthBlock(dataBits, additionalDataBits)
+	}
+
+	options := map[string]func() *gcm{
+		"mte": func() *gcm {
+			g := newGCM(block)
+			g.macPlaintext = true
+			return g
+		},
+		"reduction": func() *gcm { return newGCMWithReduction(block, variantReduction) },
+		"subkey":    func() *gcm { return newGCMWithSubkey(block, h) },
+		"length block": func() *gcm {
+			g := newGCM(block)
+			g.SetLengthBlock(swapped)
+			return g
+		},
+	}
+
+	plaintext := make([]byte, 200)
+	ad := []byte("additional data")
+	for name, base := range options {
+		var checkpoints []Checkpoint
+		e := base().newEncrypter(nonce, ad)
+		e.SetCheckpoints(2, func(cp Checkpoint) { checkpoints = append(checkpoints, cp) })
+		ciphertext := e.Encrypt(nil, plaintext)
+		tag := e.Tag()
+		assert.NotEqual(t, newGCMEncrypter(block, nonce, ad).Tag(), tag, name)
+
+		assert.Len(t, checkpoints, 6, name)
+		for _, cp := range checkpoints {
+			r := base().newEncrypter(nonce, nil)
+			r.Resume(cp)
+			assert.Equal(t, ciphertext[cp.DataLength:], r.Encrypt(nil, plaintext[cp.DataLength:]), name)
+			assert.Equal(t, tag, r.Tag(), name)
+
+			d := base().newDecrypter(nonce, nil)
+			d.Resume(cp)
+			_, err := d.Decrypt(nil, ciphertext[cp.DataLength:])
+			assert.Nil(t, err, name)
+			assert.Nil(t, d.VerifyArray(tag), name)
+		}
+	}
+}
+
+func TestResumeMisuse(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var cp Checkpoint
+	e := newGCMEncrypter(block, nonce, nil)
+	e.SetCheckpoints(1, func(c Checkpoint) { cp = c })
+	e.Encrypt(nil, make([]byte, 16))
+
+	assert.Panics(t, func() { e.Resume(cp) })
+	assert.Panics(t, func() { newLazyGCMEncrypter(block, nonce, nil).Resume(cp) })
+	assert.Panics(t, func() {
+		d := newGCMDecrypter(block, nonce, nil)
+		d.Decrypt(nil, make([]byte, 16))
+		d.Resume(cp)
+	})
+
+	cp.DataLength = 8
+	assert.Panics(t, func() { newGCMEncrypter(block, nonce, nil).Resume(cp) })
+}
+
+func TestDecrypterCheckpoints(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	ciphertext := newGCMEncrypter(block, nonce, nil).Encrypt(nil, make([]byte, 100))
+
+	var encrypted, decrypted []Checkpoint
+	e := newGCMEncrypter(block, nonce, nil)
+	e.SetCheckpoints(1, func(cp Checkpoint) { encrypted = append(encrypted, cp) })
+	e.Encrypt(nil, make([]byte, 100))
+
+	d := newGCMDecrypter(block, nonce, nil)
+	d.SetCheckpoints(1, func(cp Checkpoint) { decrypted = append(decrypted, cp) })
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+
+	assert.Len(t, decrypted, 6)
+	assert.Equal(t, encrypted, decrypted)
+
+	assert.Panics(t, func() { newLazyGCMEncrypter(block, nonce, nil).SetCheckpoints(1, func(Checkpoint) {}) })
+}
+
+func TestEncryptZerosCheckpoints(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, mte := range []bool{false, true} {
+		var zeros, plain []Checkpoint
+		z := newGCMEncrypter(block, nonce, nil)
+		p := newGCMEncrypter(block, nonce, nil)
+		z.macPlaintext, p.macPlaintext = mte, mte
+		z.SetCheckpoints(1, func(cp Checkpoint) { zeros = append(zeros, cp) })
+		p.SetCheckpoints(1, func(cp Checkpoint) { plain = append(plain, cp) })
+
+		// 8 bytes first, so the 72-byte call crosses five boundaries
+		// mid-call.
+		z.EncryptZeros(nil, 8)
+		z.EncryptZeros(nil, 72)
+		p.Encrypt(nil, make([]byte, 80))
+
+		assert.Len(t, zeros, 5, "mte %v", mte)
+		assert.Equal(t, plain, zeros, "mte %v", mte)
+		assert.Equal(t, p.Tag(), z.Tag(), "mte %v", mte)
+	}
+}
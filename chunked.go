@@ -0,0 +1,103 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"math"
+)
+
+const chunkPrefixSize = 4
+
+// ChunkedEncrypter encrypts a message as a sequence of length-prefixed chunks
+// authenticated under a single tag.
+type ChunkedEncrypter struct {
+	*Encrypter
+}
+
+// ChunkedDecrypter decrypts the frames produced by a ChunkedEncrypter.
+type ChunkedDecrypter struct {
+	*Decrypter
+}
+
+func newChunkedEncrypter(cipher cipher.Block, nonce, additionalData []byte) *ChunkedEncrypter {
+	return &ChunkedEncrypter{newGCMEncrypter(cipher, nonce, additionalData)}
+}
+
+func newChunkedDecrypter(cipher cipher.Block, nonce, additionalData []byte) *ChunkedDecrypter {
+	return &ChunkedDecrypter{newGCMDecrypter(cipher, nonce, additionalData)}
+}
+
+// Encrypt appends a frame holding the encrypted plaintext to dst. A frame is
+// the chunk length as a 4-byte big-endian integer followed by the ciphertext.
+// The length prefix is not encrypted but is authenticated as additional data,
+// so reordering, resizing or dropping frames invalidates the tag. Each length
+// prefix and each chunk is zero-padded to a block boundary in GHASH.
+func (c *ChunkedEncrypter) Encrypt(dst, plaintext []byte) []byte {
+	if uint64(len(plaintext)) > math.MaxUint32 {
+		panic("gcm: chunk too large")
+	}
+
+	ret, out := sliceForAppend(dst, chunkPrefixSize+len(plaintext))
+	if inexactOverlap(out[chunkPrefixSize:], plaintext) {
+		panic("gcm: invalid buffer overlap")
+	}
+	if err := c.checkFrame(len(plaintext)); err != nil {
+		panic(err)
+	}
+
+	binary.BigEndian.PutUint32(out, uint32(len(plaintext)))
+
+	c.flush()
+	c.update(&c.ghash, out[:chunkPrefixSize])
+	c.additionalDataNb += chunkPrefixSize
+
+	c.Encrypter.Encrypt(out[chunkPrefixSize:chunkPrefixSize], plaintext)
+	return ret
+}
+
+// Decrypt decrypts every complete frame at the start of src and appends the
+// plaintexts to dst. Trailing bytes that do not yet form a complete frame are
+// returned as rest so they can be passed again once more data has arrived.
+func (c *ChunkedDecrypter) Decrypt(dst, src []byte) (ret, rest []byte, err error) {
+	ret = dst
+
+	for len(src) >= chunkPrefixSize {
+		n := binary.BigEndian.Uint32(src)
+		if uint64(len(src)-chunkPrefixSize) < uint64(n) {
+			break
+		}
+
+		ciphertext := src[chunkPrefixSize : chunkPrefixSize+n]
+		if _, out := sliceForAppend(ret, len(ciphertext)); inexactOverlap(out, ciphertext) {
+			panic("gcm: invalid buffer overlap")
+		}
+		if err := c.checkFrame(len(ciphertext)); err != nil {
+			return nil, nil, err
+		}
+
+		c.flush()
+		c.update(&c.ghash, src[:chunkPrefixSize])
+		c.additionalDataNb += chunkPrefixSize
+
+		ret, err = c.Decrypter.Decrypt(ret, ciphertext)
+		if err != nil {
+			return nil, nil, err
+		}
+		src = src[chunkPrefixSize+n:]
+	}
+
+	return ret, src, nil
+}
+
+// checkFrame returns the error that encrypting or decrypting n bytes would
+// fail with, so that it is reported before the frame's length prefix is
+// hashed and a failed frame leaves GHASH and the lengths untouched.
+func (g *gcm) checkFrame(n int) error {
+	if g.discarded {
+		return ErrDiscarded
+	}
+	if g.finalized {
+		return ErrFinalized
+	}
+	return g.checkKeystream(n)
+}
@@ -0,0 +1,128 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func sealChunks(t *testing.T, chunks ...[]byte) ([]byte, [gcmTagSize]byte) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	enc := newChunkedEncrypter(block, nonce, nil)
+
+	var frames []byte
+	for _, chunk := range chunks {
+		frames = enc.Encrypt(frames, chunk)
+	}
+
+	return frames, enc.Tag()
+}
+
+func openChunks(t *testing.T, frames []byte, tag [gcmTagSize]byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	dec := newChunkedDecrypter(block, nonce, nil)
+
+	plaintext, rest, err := dec.Decrypt(nil, frames)
+	assert.Nil(t, err)
+	assert.Empty(t, rest)
+
+	return plaintext, dec.Verify(tag[:])
+}
+
+func TestChunkedRoundTrip(t *testing.T) {
+	frames, tag := sealChunks(t, decryptedPacket[:4], decryptedPacket[4:], nil)
+
+	plaintext, err := openChunks(t, frames, tag)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+}
+
+func TestChunkedPartialFrames(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	frames, tag := sealChunks(t, decryptedPacket[:7], decryptedPacket[7:])
+
+	dec := newChunkedDecrypter(block, nonce, nil)
+
+	var plaintext []byte
+	var pending []byte
+	for _, b := range frames {
+		plaintext, pending, err = dec.Decrypt(plaintext, append(pending, b))
+		assert.Nil(t, err)
+	}
+
+	assert.Empty(t, pending)
+	assert.Equal(t, decryptedPacket, plaintext)
+	assert.Nil(t, dec.Verify(tag[:]))
+}
+
+func TestChunkedTamper(t *testing.T) {
+	a, b := decryptedPacket[:10], decryptedPacket[10:]
+	frames, tag := sealChunks(t, a, b)
+
+	flipped := append([]byte{}, frames...)
+	flipped[chunkPrefixSize] ^= 1
+	_, err := openChunks(t, flipped, tag)
+	assert.ErrorIs(t, err, ErrOpen)
+
+	first := chunkPrefixSize + len(a)
+	reordered := append(append([]byte{}, frames[first:]...), frames[:first]...)
+	_, err = openChunks(t, reordered, tag)
+	assert.ErrorIs(t, err, ErrOpen)
+
+	_, err = openChunks(t, frames[:first], tag)
+	assert.ErrorIs(t, err, ErrOpen)
+
+	// Same ciphertext bytes, split at a different point.
+	resplit, _ := sealChunks(t, decryptedPacket[:5], decryptedPacket[5:])
+	_, err = openChunks(t, resplit, tag)
+	assert.ErrorIs(t, err, ErrOpen)
+}
+
+func TestChunkedFailedFrameLeavesStateAlone(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	frames, tag := sealChunks(t, decryptedPacket[:10], decryptedPacket[10:])
+	first := chunkPrefixSize + 10
+
+	// Exhaust the counter so the next frame fails its keystream check.
+	exhaust := func(g *gcm) {
+		used := binary.BigEndian.Uint32(g.initialCounter[gcmBlockSize-4:]) + maxKeystreamBlocks
+		binary.BigEndian.PutUint32(g.counter[gcmBlockSize-4:], used)
+	}
+
+	dec := newChunkedDecrypter(block, nonce, nil)
+	_, _, err = dec.Decrypt(nil, frames[:first])
+	assert.Nil(t, err)
+	counter, ghash, adNb := dec.counter, dec.ghash, dec.additionalDataNb
+	exhaust(dec.gcm)
+	_, _, err = dec.Decrypt(nil, frames[first:])
+	assert.Equal(t, ErrCounterExhausted, err)
+	assert.Equal(t, ghash, dec.ghash)
+	assert.Equal(t, adNb, dec.additionalDataNb)
+
+	// With the counter restored the message still verifies.
+	dec.counter = counter
+	_, _, err = dec.Decrypt(nil, frames[first:])
+	assert.Nil(t, err)
+	assert.Nil(t, dec.Verify(tag[:]))
+
+	_, _, err = dec.Decrypt(nil, frames[:first])
+	assert.Equal(t, ErrFinalized, err)
+
+	enc := newChunkedEncrypter(block, nonce, nil)
+	enc.Encrypt(nil, decryptedPacket[:10])
+	ghash, adNb = enc.ghash, enc.additionalDataNb
+	exhaust(enc.gcm)
+	assert.PanicsWithValue(t, ErrCounterExhausted, func() { enc.Encrypt(nil, decryptedPacket[10:]) })
+	assert.Equal(t, ghash, enc.ghash)
+	assert.Equal(t, adNb, enc.additionalDataNb)
+}
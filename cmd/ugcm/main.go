@@ -0,0 +1,177 @@
+// Command ugcm encrypts or decrypts stdin to stdout with AES-GCM, streaming
+// so that inputs of any size can be processed.
+//
+// Encrypted output is nonce||ciphertext||tag, unless -nonce is given, in
+// which case the nonce is omitted. When decrypting, plaintext is written as
+// it is produced, before the tag has been checked. A non-zero exit status
+// means the tag did not match and everything written must be discarded.
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	uncheckedgcm "github.com/cedws/unchecked-gcm"
+)
+
+const (
+	nonceSize = 16
+	tagSize   = 16
+	chunkSize = 64 * 1024
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "ugcm:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	flags := flag.NewFlagSet("ugcm", flag.ContinueOnError)
+	decrypt := flags.Bool("d", false, "decrypt instead of encrypt")
+	keyHex := flags.String("key", "", "AES key as hex")
+	keyFile := flags.String("keyfile", "", "file containing the AES key as hex")
+	nonceHex := flags.String("nonce", "", "nonce as hex; if unset, it is generated and prepended when encrypting and read from the input when decrypting")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	key, err := readKey(*keyHex, *keyFile)
+	if err != nil {
+		return err
+	}
+
+	var nonce []byte
+	if *nonceHex != "" {
+		nonce, err = hex.DecodeString(*nonceHex)
+		if err != nil {
+			return fmt.Errorf("decoding nonce: %w", err)
+		}
+	}
+
+	out := bufio.NewWriter(stdout)
+	if *decrypt {
+		err = decryptStream(out, stdin, key, nonce)
+	} else {
+		err = encryptStream(out, stdin, key, nonce)
+	}
+	if err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+func readKey(keyHex, keyFile string) ([]byte, error) {
+	if (keyHex == "") == (keyFile == "") {
+		return nil, errors.New("exactly one of -key and -keyfile is required")
+	}
+
+	if keyFile != "" {
+		contents, err := os.ReadFile(keyFile)
+		if err != nil {
+			return nil, err
+		}
+		keyHex = strings.TrimSpace(string(contents))
+	}
+
+	key, err := hex.DecodeString(keyHex)
+	if err != nil {
+		return nil, fmt.Errorf("decoding key: %w", err)
+	}
+	return key, nil
+}
+
+func encryptStream(w io.Writer, r io.Reader, key, nonce []byte) error {
+	if nonce == nil {
+		nonce = make([]byte, nonceSize)
+		if _, err := rand.Read(nonce); err != nil {
+			return err
+		}
+		if _, err := w.Write(nonce); err != nil {
+			return err
+		}
+	}
+
+	e, err := uncheckedgcm.NewFromKey(key, nonce, nil)
+	if err != nil {
+		return err
+	}
+
+	buf := make([]byte, chunkSize)
+	var ciphertext []byte
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			ciphertext = e.Encrypt(ciphertext[:0], buf[:n])
+			if _, err := w.Write(ciphertext); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	tag := e.Tag()
+	_, err = w.Write(tag[:])
+	return err
+}
+
+func decryptStream(w io.Writer, r io.Reader, key, nonce []byte) error {
+	if nonce == nil {
+		nonce = make([]byte, nonceSize)
+		if _, err := io.ReadFull(r, nonce); err != nil {
+			return fmt.Errorf("reading nonce: %w", err)
+		}
+	}
+
+	d, err := uncheckedgcm.NewDecrypterFromKey(key, nonce, nil)
+	if err != nil {
+		return err
+	}
+
+	// The last tagSize bytes of the input are the tag, so always hold back
+	// that many bytes until the end of the input is reached.
+	buf := make([]byte, chunkSize+tagSize)
+	held := 0
+	var plaintext []byte
+	for {
+		n, err := r.Read(buf[held:])
+		held += n
+
+		if held > tagSize {
+			out, err := d.Decrypt(plaintext[:0], buf[:held-tagSize])
+			if err != nil {
+				return err
+			}
+			plaintext = out
+			if _, err := w.Write(plaintext); err != nil {
+				return err
+			}
+			held = copy(buf, buf[held-tagSize:held])
+		}
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if held < tagSize {
+		return errors.New("input too short to contain a tag")
+	}
+	return d.Verify(buf[:tagSize])
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+	"testing/iotest"
+
+	uncheckedgcm "github.com/cedws/unchecked-gcm"
+	"github.com/stretchr/testify/assert"
+)
+
+var testKey = "000102030405060708090a0b0c0d0e0f"
+
+func TestRoundTrip(t *testing.T) {
+	for _, size := range []int{0, 1, 15, 16, 17, chunkSize - 1, chunkSize, 3*chunkSize + 5} {
+		plaintext := make([]byte, size)
+		for i := range plaintext {
+			plaintext[i] = byte(i)
+		}
+
+		var encrypted bytes.Buffer
+		err := run([]string{"-key", testKey}, bytes.NewReader(plaintext), &encrypted)
+		assert.Nil(t, err)
+		assert.Equal(t, nonceSize+size+tagSize, encrypted.Len())
+
+		// Feed the decrypter one byte at a time to exercise tag hold-back.
+		var decrypted bytes.Buffer
+		err = run([]string{"-d", "-key", testKey}, iotest.OneByteReader(bytes.NewReader(encrypted.Bytes())), &decrypted)
+		assert.Nil(t, err)
+		assert.True(t, bytes.Equal(plaintext, decrypted.Bytes()))
+	}
+}
+
+func TestMatchesStdlib(t *testing.T) {
+	key, _ := hex.DecodeString(testKey)
+	nonce := "101112131415161718191a1b"
+	plaintext := []byte("compare with crypto/cipher")
+
+	var encrypted bytes.Buffer
+	err := run([]string{"-key", testKey, "-nonce", nonce}, bytes.NewReader(plaintext), &encrypted)
+	assert.Nil(t, err)
+
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	aead, err := cipher.NewGCM(block)
+	assert.Nil(t, err)
+	nonceBytes, _ := hex.DecodeString(nonce)
+	assert.Equal(t, aead.Seal(nil, nonceBytes, plaintext, nil), encrypted.Bytes())
+}
+
+func TestTampered(t *testing.T) {
+	var encrypted bytes.Buffer
+	err := run([]string{"-key", testKey}, bytes.NewReader([]byte("tamper with me")), &encrypted)
+	assert.Nil(t, err)
+
+	ciphertext := encrypted.Bytes()
+	ciphertext[nonceSize] ^= 1
+
+	err = run([]string{"-d", "-key", testKey}, bytes.NewReader(ciphertext), &bytes.Buffer{})
+	assert.ErrorIs(t, err, uncheckedgcm.ErrOpen)
+
+	err = run([]string{"-d", "-key", testKey}, bytes.NewReader(ciphertext[:nonceSize+tagSize-1]), &bytes.Buffer{})
+	assert.NotNil(t, err)
+}
+
+func TestInvalidArguments(t *testing.T) {
+	assert.NotNil(t, run(nil, &bytes.Buffer{}, &bytes.Buffer{}))
+	assert.NotNil(t, run([]string{"-key", "00"}, &bytes.Buffer{}, &bytes.Buffer{}))
+	assert.NotNil(t, run([]string{"-key", "zz"}, &bytes.Buffer{}, &bytes.Buffer{}))
+}
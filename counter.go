@@ -0,0 +1,33 @@
+package uncheckedgcm
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// maxKeystreamBlocks is the most keystream one message may use: the 32-bit
+// counter has 2^32 values, one of which is J0, and continuing past the last
+// would wrap around to keystream already used.
+const maxKeystreamBlocks = 1<<32 - 2
+
+// ErrCounterExhausted is returned by Decrypt, and Encrypt panics with it,
+// when processing the data would need more than 2^32-2 blocks of keystream
+// in one message.
+var ErrCounterExhausted = errors.New("gcm: message too long, counter would wrap")
+
+// checkKeystream reports whether n more bytes can be processed without the
+// counter wrapping around.
+func (g *gcm) checkKeystream(n int) error {
+	need := n - len(g.extraMask)
+	if need <= 0 {
+		return nil
+	}
+	blocks := (uint64(need) + gcmBlockSize - 1) / gcmBlockSize
+
+	used := binary.BigEndian.Uint32(g.counter[gcmBlockSize-4:]) -
+		binary.BigEndian.Uint32(g.initialCounter[gcmBlockSize-4:])
+	if uint64(used)+blocks > maxKeystreamBlocks {
+		return ErrCounterExhausted
+	}
+	return nil
+}
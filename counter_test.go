@@ -0,0 +1,63 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// setBlocksUsed positions the counter as if n blocks of keystream had
+// already been used, so the wraparound guard can be reached without
+// encrypting 64 GiB.
+func (g *gcm) setBlocksUsed(n uint32) {
+	g.extraMask = g.extraMask[:0]
+	g.counter = g.initialCounter
+	binary.BigEndian.PutUint32(g.counter[gcmBlockSize-4:],
+		binary.BigEndian.Uint32(g.initialCounter[gcmBlockSize-4:])+n)
+}
+
+func TestCounterExhausted(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	e.setBlocksUsed(maxKeystreamBlocks - 2)
+
+	// Two blocks remain; a partial third is refused before any state
+	// changes.
+	e.Encrypt(nil, make([]byte, gcmBlockSize+1))
+	e.Encrypt(nil, make([]byte, gcmBlockSize-1))
+	assert.PanicsWithValue(t, ErrCounterExhausted, func() { e.Encrypt(nil, []byte{0}) })
+	e.Encrypt(nil, nil)
+
+	d := newGCMDecrypter(block, nonce, nil)
+	d.setBlocksUsed(maxKeystreamBlocks - 1)
+	_, err = d.Decrypt(nil, make([]byte, gcmBlockSize))
+	assert.Nil(t, err)
+	nb := d.ciphertextNb
+	_, err = d.Decrypt(nil, []byte{0})
+	assert.ErrorIs(t, err, ErrCounterExhausted)
+	assert.Equal(t, nb, d.ciphertextNb)
+
+	r := newGCMEncrypter(block, nonce, nil)
+	r.setBlocksUsed(maxKeystreamBlocks - 1)
+	assert.PanicsWithValue(t, ErrCounterExhausted, func() { r.Reserve(2 * gcmBlockSize) })
+	r.Reserve(gcmBlockSize)
+}
+
+func TestCounterExhaustedWrapsLowWord(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// Start the counter just below the 32-bit boundary so the guard has to
+	// handle the low word wrapping mid-message.
+	e := newGCMEncrypter(block, nonce[:gcmStandardNonceSize], nil)
+	e.SetInitialCounterValue(0xfffffffe)
+	e.Encrypt(nil, make([]byte, 4*gcmBlockSize))
+	assert.Nil(t, e.checkKeystream(gcmBlockSize))
+
+	e.setBlocksUsed(maxKeystreamBlocks)
+	assert.Equal(t, ErrCounterExhausted, e.checkKeystream(1))
+}
@@ -0,0 +1,28 @@
+//go:build ugcm_debug
+
+package uncheckedgcm
+
+import "encoding/binary"
+
+// HashSubkey returns the hash subkey H, the encryption of the zero block,
+// for recomputing GHASH against a reference implementation. It is only
+// built with the ugcm_debug tag.
+//
+// H is derived from the key and is enough to forge tags for any nonce whose
+// tag mask is known. It must never be logged or exported from a production
+// build.
+func (g *gcm) HashSubkey() [gcmBlockSize]byte {
+	var h [gcmBlockSize]byte
+	x := g.productTable[reverseBits(1)]
+	binary.BigEndian.PutUint64(h[:8], x.low)
+	binary.BigEndian.PutUint64(h[8:], x.high)
+	return h
+}
+
+// TagMask returns E(J0), the block XORed into the GHASH output to form the
+// tag. Comparing it between implementations separates counter derivation
+// bugs from GHASH bugs. It is only built with the ugcm_debug tag and, like
+// HashSubkey, must never be logged from a production build.
+func (g *gcm) TagMask() [gcmBlockSize]byte {
+	return g.tagMask
+}
@@ -0,0 +1,59 @@
+//go:build ugcm_debug
+
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHashSubkey(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var want [gcmBlockSize]byte
+	block.Encrypt(want[:], want[:])
+
+	g := newGCMEncrypter(block, nonce, nil)
+	assert.Equal(t, want, g.HashSubkey())
+
+	// Recompute the tag from H alone.
+	plaintext := []byte("hash subkey")
+	ciphertext := g.Encrypt(nil, plaintext)
+
+	h := g.HashSubkey()
+	ref := &referenceGHASH{h: gcmFieldElement{
+		binary.BigEndian.Uint64(h[:8]),
+		binary.BigEndian.Uint64(h[8:]),
+	}}
+	ref.Update(ciphertext)
+	lengths := StandardLengthBlock(0, uint64(len(ciphertext))*8)
+	ref.Update(lengths[:])
+	sum := ref.Sum()
+
+	tag := g.Tag()
+	for i := range sum {
+		sum[i] ^= g.tagMask[i]
+	}
+	assert.Equal(t, tag, sum)
+}
+
+func TestTagMask(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, n := range [][]byte{nonce, nonce[:gcmStandardNonceSize]} {
+		g := newGCMDecrypter(block, n, nil)
+
+		// J0 is one counter step before the first keystream block.
+		j0 := g.InitialCounter()
+		binary.BigEndian.PutUint32(j0[12:], binary.BigEndian.Uint32(j0[12:])-1)
+
+		var want [gcmBlockSize]byte
+		block.Encrypt(want[:], j0[:])
+		assert.Equal(t, want, g.TagMask())
+	}
+}
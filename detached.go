@@ -0,0 +1,46 @@
+package uncheckedgcm
+
+// SealDetached encrypts plaintext and returns the ciphertext and tag
+// separately. It is meant to be called once on a fresh encrypter.
+func (g *Encrypter) SealDetached(plaintext []byte) ([]byte, [gcmTagSize]byte) {
+	ciphertext := g.Encrypt(nil, plaintext)
+	return ciphertext, g.Tag()
+}
+
+// Sum appends the tag to b and returns the result, like hash.Hash.Sum. It
+// finalizes the encrypter in the same way as Tag, so calling it again
+// appends the same tag.
+func (g *Encrypter) Sum(b []byte) []byte {
+	tag := g.Tag()
+	return append(b, tag[:]...)
+}
+
+// OpenDetached is the same as OpenVerified.
+func (g *Decrypter) OpenDetached(ciphertext, tag []byte) ([]byte, error) {
+	return g.OpenVerified(ciphertext, tag)
+}
+
+// OpenVerified decrypts ciphertext and checks it against tag in constant
+// time, returning the plaintext only if the tag matches. On failure the
+// decrypted bytes are zeroed before ErrOpen is returned, so unverified
+// plaintext never reaches the caller. It is meant to be called once on a
+// fresh decrypter, and is the recommended way to decrypt with this package
+// unless plaintext really is needed before the tag is available.
+func (g *Decrypter) OpenVerified(ciphertext, tag []byte) ([]byte, error) {
+	return g.openVerified(nil, ciphertext, tag)
+}
+
+// openVerified is OpenVerified decrypting into buf.
+func (g *Decrypter) openVerified(buf, ciphertext, tag []byte) ([]byte, error) {
+	plaintext, err := g.Decrypt(buf[:0], ciphertext)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.Verify(tag); err != nil {
+		clear(plaintext)
+		return nil, err
+	}
+
+	return plaintext, nil
+}
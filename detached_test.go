@@ -0,0 +1,93 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetachedRoundTrip(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	additionalData := []byte("header")
+
+	ciphertext, tag := newGCMEncrypter(block, nonce, additionalData).SealDetached(decryptedPacket)
+	assert.Equal(t, encryptedPacket, ciphertext)
+
+	plaintext, err := newGCMDecrypter(block, nonce, additionalData).OpenDetached(ciphertext, tag[:])
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+}
+
+func TestOpenDetachedTampered(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	ciphertext, tag := newGCMEncrypter(block, nonce, nil).SealDetached(decryptedPacket)
+
+	tag[0] ^= 1
+	plaintext, err := newGCMDecrypter(block, nonce, nil).OpenDetached(ciphertext, tag[:])
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.Nil(t, plaintext)
+
+	tag[0] ^= 1
+	ciphertext[0] ^= 1
+	plaintext, err = newGCMDecrypter(block, nonce, nil).OpenDetached(ciphertext, tag[:])
+	assert.ErrorIs(t, err, ErrOpen)
+	assert.Nil(t, plaintext)
+
+	_, err = newGCMDecrypter(block, nonce, nil).OpenDetached(ciphertext, tag[:12])
+	assert.ErrorIs(t, err, ErrOpen)
+}
+
+func TestOpenVerified(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	ad := []byte("header")
+	ciphertext, tag := newGCMEncrypter(block, nonce, ad).SealDetached(decryptedPacket)
+
+	plaintext, err := newGCMDecrypter(block, nonce, ad).OpenVerified(ciphertext, tag[:])
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+
+	tamperedCiphertext := append([]byte(nil), ciphertext...)
+	tamperedCiphertext[3] ^= 0x80
+
+	for _, tc := range []struct {
+		name           string
+		ad, ciphertext []byte
+	}{
+		{"ciphertext", ad, tamperedCiphertext},
+		{"additional data", []byte("Header"), ciphertext},
+	} {
+		buf := make([]byte, len(ciphertext))
+		plaintext, err := newGCMDecrypter(block, nonce, tc.ad).openVerified(buf, tc.ciphertext, tag[:])
+		assert.Equal(t, ErrOpen, err, tc.name)
+		assert.Nil(t, plaintext, tc.name)
+
+		// The work buffer held the decryption and must have been wiped.
+		assert.Equal(t, make([]byte, len(ciphertext)), buf, tc.name)
+	}
+}
+
+func TestSum(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, []byte("gmac"))
+	tag := e.Tag()
+
+	// GMAC: no plaintext, only additional data.
+	g := newGCMEncrypter(block, nonce, []byte("gmac"))
+	sum := g.Sum([]byte("prefix"))
+	assert.Equal(t, append([]byte("prefix"), tag[:]...), sum)
+	assert.Equal(t, tag[:], g.Sum(nil))
+
+	a := NewAuthenticator(block, nonce, []byte("gmac"))
+	assert.Equal(t, sum, a.Sum([]byte("prefix")))
+	assert.Equal(t, tag[:], a.Sum(nil))
+	assert.PanicsWithValue(t, ErrFinalized, func() { a.Update([]byte("late")) })
+}
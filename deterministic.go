@@ -0,0 +1,94 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+)
+
+// DeterministicEncrypter seals messages under a synthetic nonce computed
+// from the additional data and the plaintext, so the same message always
+// produces the same output and different messages get different nonces.
+// This suits deduplicating stores.
+//
+// It leaks plaintext equality: anyone can see that two outputs are equal,
+// and so that their plaintexts are. It is not AES-GCM-SIV and does not
+// interoperate with it. Sealing needs the whole plaintext before any
+// ciphertext can be produced.
+type DeterministicEncrypter struct {
+	base           *gcm
+	macKey         []byte
+	additionalData []byte
+}
+
+// NewDeterministicEncrypter returns a DeterministicEncrypter for cipher
+// that authenticates additionalData with every message. The HMAC key for
+// the synthetic nonce is two blocks of cipher output on fixed inputs, kept
+// apart from the hash subkey, which is the encryption of the zero block.
+func NewDeterministicEncrypter(cipher cipher.Block, additionalData []byte) *DeterministicEncrypter {
+	macKey := make([]byte, 2*gcmBlockSize)
+	var label [gcmBlockSize]byte
+	copy(label[:], "ugcm synth nonce")
+	cipher.Encrypt(macKey[:gcmBlockSize], label[:])
+	label[gcmBlockSize-1] ^= 1
+	cipher.Encrypt(macKey[gcmBlockSize:], label[:])
+
+	return &DeterministicEncrypter{
+		base:           newGCM(cipher),
+		macKey:         macKey,
+		additionalData: append([]byte{}, additionalData...),
+	}
+}
+
+// syntheticNonce returns HMAC-SHA256 over the additional data length, the
+// additional data and the plaintext, truncated to a nonce. The length
+// prefix keeps the boundary between the two unambiguous.
+func (d *DeterministicEncrypter) syntheticNonce(plaintext []byte) []byte {
+	mac := hmac.New(sha256.New, d.macKey)
+
+	var length [8]byte
+	binary.BigEndian.PutUint64(length[:], uint64(len(d.additionalData)))
+	mac.Write(length[:])
+	mac.Write(d.additionalData)
+	mac.Write(plaintext)
+
+	return mac.Sum(nil)[:gcmNonceSize]
+}
+
+// Seal appends nonce||ciphertext||tag for plaintext to dst. The nonce goes
+// through the usual GHASH counter derivation.
+func (d *DeterministicEncrypter) Seal(dst, plaintext []byte) []byte {
+	nonce := d.syntheticNonce(plaintext)
+	g := d.base.fork().newEncrypter(nonce, d.additionalData)
+
+	dst = append(dst, nonce...)
+	dst = g.Encrypt(dst, plaintext)
+	return g.Sum(dst)
+}
+
+// Open authenticates and decrypts the output of Seal, appending the
+// plaintext to dst. As well as the tag, the nonce must be the one the
+// plaintext would produce. Either failure returns ErrOpen and no plaintext.
+func (d *DeterministicEncrypter) Open(dst, sealed []byte) ([]byte, error) {
+	if len(sealed) < gcmNonceSize+gcmTagSize {
+		return nil, ErrOpen
+	}
+
+	nonce := sealed[:gcmNonceSize]
+	ciphertext := sealed[gcmNonceSize : len(sealed)-gcmTagSize]
+	tag := sealed[len(sealed)-gcmTagSize:]
+
+	g := d.base.fork().newDecrypter(nonce, d.additionalData)
+	plaintext, err := g.OpenVerified(ciphertext, tag)
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare(d.syntheticNonce(plaintext), nonce) != 1 {
+		clear(plaintext)
+		return nil, ErrOpen
+	}
+
+	return append(dst, plaintext...), nil
+}
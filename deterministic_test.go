@@ -0,0 +1,58 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeterministicEncrypter(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	d := NewDeterministicEncrypter(block, []byte("ad"))
+
+	a := d.Seal(nil, decryptedPacket)
+	b := NewDeterministicEncrypter(block, []byte("ad")).Seal(nil, decryptedPacket)
+	assert.Equal(t, a, b)
+	assert.Len(t, a, gcmNonceSize+len(decryptedPacket)+gcmTagSize)
+
+	other := append([]byte{}, decryptedPacket...)
+	other[len(other)-1] ^= 1
+	c := d.Seal(nil, other)
+	assert.NotEqual(t, a[:gcmNonceSize], c[:gcmNonceSize])
+	assert.NotEqual(t, a, c)
+
+	// The additional data feeds the nonce as well as the tag.
+	e := NewDeterministicEncrypter(block, []byte("other ad")).Seal(nil, decryptedPacket)
+	assert.NotEqual(t, a[:gcmNonceSize], e[:gcmNonceSize])
+
+	// The nonce is an ordinary GCM nonce.
+	g := newGCMEncrypter(block, a[:gcmNonceSize], []byte("ad"))
+	assert.Equal(t, a[gcmNonceSize:], g.Sum(g.Encrypt(nil, decryptedPacket)))
+
+	plaintext, err := d.Open(nil, a)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+
+	a[gcmNonceSize] ^= 1
+	_, err = d.Open(nil, a)
+	assert.Equal(t, ErrOpen, err)
+
+	_, err = d.Open(nil, a[:gcmNonceSize+gcmTagSize-1])
+	assert.Equal(t, ErrOpen, err)
+}
+
+func TestDeterministicRejectsForeignNonce(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// A valid GCM message under a nonce that is not the synthetic one is
+	// rejected.
+	g := newGCMEncrypter(block, nonce, []byte("ad"))
+	sealed := append(append([]byte{}, nonce...), g.Sum(g.Encrypt(nil, decryptedPacket))...)
+
+	_, err = NewDeterministicEncrypter(block, []byte("ad")).Open(nil, sealed)
+	assert.Equal(t, ErrOpen, err)
+}
@@ -0,0 +1,27 @@
+package uncheckedgcm
+
+import "errors"
+
+// ErrDiscarded is returned when a decrypter is used after Discard.
+var ErrDiscarded = errors.New("gcm: use of discarded instance")
+
+// Discard abandons the message in progress. The keystream, counters, GHASH
+// state, tag mask and hash subkey are zeroed and the instance is marked dead:
+// afterwards Decrypt and Verify return ErrDiscarded and every other method
+// panics. Unlike finishing a message with Tag or Verify, Discard means the
+// data processed so far must not be used.
+func (g *gcm) Discard() {
+	clear(g.extraMask)
+	clear(g.lazyAD)
+	*g = gcm{
+		cipher:    g.cipher,
+		discarded: true,
+	}
+}
+
+// mustBeLive panics if g has been discarded.
+func (g *gcm) mustBeLive() {
+	if g.discarded {
+		panic(ErrDiscarded)
+	}
+}
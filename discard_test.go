@@ -0,0 +1,50 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscardEncrypter(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	g := newGCMEncrypter(block, nonce, []byte("ad"))
+	g.Encrypt(nil, make([]byte, 21))
+	extraMask := g.extraMask
+	assert.NotEmpty(t, extraMask)
+
+	g.Discard()
+
+	assert.Equal(t, make([]byte, len(extraMask)), extraMask)
+	assert.Zero(t, g.ghash)
+	assert.Zero(t, g.counter)
+	assert.Zero(t, g.tagMask)
+	assert.Zero(t, g.productTable)
+
+	assert.PanicsWithValue(t, ErrDiscarded, func() { g.Encrypt(nil, []byte("more")) })
+	assert.PanicsWithValue(t, ErrDiscarded, func() { g.Tag() })
+	assert.PanicsWithValue(t, ErrDiscarded, func() { g.PeekTag() })
+}
+
+func TestDiscardDecrypter(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, make([]byte, 40))
+	tag := e.Tag()
+
+	g := newGCMDecrypter(block, nonce, nil)
+	_, err = g.Decrypt(nil, ciphertext[:20])
+	assert.Nil(t, err)
+
+	g.Discard()
+
+	_, err = g.Decrypt(nil, ciphertext[20:])
+	assert.ErrorIs(t, err, ErrDiscarded)
+	assert.ErrorIs(t, g.VerifyArray(tag), ErrDiscarded)
+	assert.PanicsWithValue(t, ErrDiscarded, func() { g.Tag() })
+}
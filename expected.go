@@ -0,0 +1,21 @@
+package uncheckedgcm
+
+import "errors"
+
+var errNoExpectedTag = errors.New("gcm: no expected tag set")
+
+// SetExpectedTag records the tag to check against once all ciphertext has
+// been decrypted, for protocols that send the tag first. tag is copied.
+func (g *Decrypter) SetExpectedTag(tag []byte) {
+	g.expectedTag = append([]byte{}, tag...)
+}
+
+// VerifyExpected is Verify with the tag given to SetExpectedTag. The
+// comparison is the same constant-time check and happens only now, never
+// while ciphertext is still being processed.
+func (g *Decrypter) VerifyExpected() error {
+	if g.expectedTag == nil {
+		return errNoExpectedTag
+	}
+	return g.Verify(g.expectedTag)
+}
@@ -0,0 +1,43 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyExpected(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, []byte("ad"))
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	for _, tc := range []struct {
+		tag  []byte
+		want error
+	}{
+		{tag[:], nil},
+		{append([]byte{tag[0] ^ 1}, tag[1:]...), ErrOpen},
+		{tag[:8], ErrOpen},
+	} {
+		// Supplying the tag up front is equivalent to passing it to Verify.
+		d := newGCMDecrypter(block, nonce, []byte("ad"))
+		_, err := d.Decrypt(nil, ciphertext)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.want, d.Verify(tc.tag))
+
+		expected := append([]byte(nil), tc.tag...)
+		d = newGCMDecrypter(block, nonce, []byte("ad"))
+		d.SetExpectedTag(expected)
+		clear(expected)
+		_, err = d.Decrypt(nil, ciphertext)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.want, d.VerifyExpected())
+	}
+
+	d := newGCMDecrypter(block, nonce, []byte("ad"))
+	assert.Equal(t, errNoExpectedTag, d.VerifyExpected())
+}
@@ -0,0 +1,110 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// gcmStandardReduction is the reduction polynomial of GCM,
+// x^128 + x^7 + x^2 + x + 1, with the x^128 term dropped and the remaining
+// coefficients in GCM's reflected bit order, so that x^0 is the most
+// significant bit.
+const gcmStandardReduction = 0xe100000000000000
+
+// gcmField is GF(2^128) defined by x^128 + p(x), where reduction holds p in
+// the same order as gcmStandardReduction. Its table holds the reduction of
+// the four bits that mul shifts out at each step.
+type gcmField struct {
+	reduction uint64
+	table     [16]uint64
+}
+
+// standardField is the field used by GCM.
+var standardField = newGCMField(gcmStandardReduction)
+
+// newGCMField returns the field reducing by x^128 + p(x). p must have degree
+// at most 60, so its three least significant bits are clear. Whether the
+// polynomial is irreducible is not checked; if it is not, the result is not
+// a field and GHASH loses its security properties.
+func newGCMField(reduction uint64) *gcmField {
+	if reduction&7 != 0 {
+		panic("gcm: reduction polynomial degree too high")
+	}
+
+	f := &gcmField{reduction: reduction}
+	for i := range f.table {
+		// Bit b of i is the coefficient shifted to x^(131-b), which
+		// reduces to x^(3-b)·p(x).
+		for b := 0; b < 4; b++ {
+			if i&(1<<b) != 0 {
+				f.table[i] ^= reduction >> (3 - b)
+			}
+		}
+	}
+	return f
+}
+
+func (f *gcmField) double(x *gcmFieldElement) (double gcmFieldElement) {
+	// All ones if the bit shifted out is set, so the reduction is applied
+	// without branching on x.
+	mask := -(x.high & 1)
+
+	double.high = x.high >> 1
+	double.high |= x.low << 63
+	double.low = x.low >> 1
+	double.low ^= f.reduction & mask
+
+	return
+}
+
+// mul returns the product of x and y. Unlike gcm.mul it does not need a
+// product table, so it can multiply by values other than H. Its operands are
+// often secret, derived from H, so each bit of x selects v with a mask
+// rather than a branch, and the running time does not depend on either.
+func (f *gcmField) mul(x, y *gcmFieldElement) (product gcmFieldElement) {
+	v := *y
+
+	for i := 0; i < 2; i++ {
+		word := x.low
+		if i == 1 {
+			word = x.high
+		}
+
+		for j := 0; j < 64; j++ {
+			mask := -(word >> 63)
+			product.low ^= v.low & mask
+			product.high ^= v.high & mask
+			v = f.double(&v)
+			word <<= 1
+		}
+	}
+
+	return
+}
+
+// pow returns x^n. It branches on the bits of n, which is always a public
+// block count, but not on x.
+func (f *gcmField) pow(x gcmFieldElement, n uint64) gcmFieldElement {
+	result := gcmOne
+	for ; n > 0; n >>= 1 {
+		if n&1 != 0 {
+			result = f.mul(&result, &x)
+		}
+		x = f.mul(&x, &x)
+	}
+	return result
+}
+
+// newGCMWithReduction is like newGCM but computes GHASH in the field
+// reducing by x^128 + p(x), with p given as for newGCMField, for
+// interoperating with systems that use a non-standard polynomial. The
+// result is not GCM unless reduction is gcmStandardReduction.
+func newGCMWithReduction(cipher cipher.Block, reduction uint64) *gcm {
+	var zero, key [gcmBlockSize]byte
+	cipher.Encrypt(key[:], zero[:])
+
+	g := &gcm{
+		cipher: cipher,
+		field:  newGCMField(reduction),
+	}
+	g.setSubkey(key)
+
+	return g
+}
@@ -0,0 +1,72 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// variantReduction is x^128 + x^7 + x^5 + x^3 + 1.
+const variantReduction = 0x9500000000000000
+
+func TestStandardFieldTable(t *testing.T) {
+	// The reduction table this package used before fields were
+	// configurable, shifted into place.
+	want := []uint16{
+		0x0000, 0x1c20, 0x3840, 0x2460, 0x7080, 0x6ca0, 0x48c0, 0x54e0,
+		0xe100, 0xfd20, 0xd940, 0xc560, 0x9180, 0x8da0, 0xa9c0, 0xb5e0,
+	}
+	for i, w := range want {
+		assert.Equal(t, uint64(w)<<48, standardField.table[i])
+	}
+
+	assert.Panics(t, func() { newGCMField(gcmStandardReduction | 1) })
+}
+
+func TestVariantField(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	variant := newGCMWithReduction(block, variantReduction)
+	standard := newGCM(block)
+	assert.Equal(t, standard.productTable[reverseBits(1)], variant.productTable[reverseBits(1)])
+
+	// The table multiply must agree with the bitwise multiply in the same
+	// field.
+	h := variant.productTable[reverseBits(1)]
+	data := make([]byte, 5*gcmBlockSize)
+	for i := range data {
+		data[i] = byte(i * 31)
+	}
+
+	var want gcmFieldElement
+	for i := 0; i < len(data); i += gcmBlockSize {
+		want.low ^= binary.BigEndian.Uint64(data[i:])
+		want.high ^= binary.BigEndian.Uint64(data[i+8:])
+		want = variant.field.mul(&want, &h)
+	}
+
+	var got, std gcmFieldElement
+	variant.update(&got, data)
+	standard.update(&std, data)
+	assert.Equal(t, want, got)
+	assert.NotEqual(t, std, got)
+
+	// With a 12-byte nonce, J0 does not go through GHASH, so only the tag
+	// changes.
+	n := nonce[:gcmStandardNonceSize]
+	e := variant.fork().newEncrypter(n, []byte("ad"))
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	reference := newGCMEncrypter(block, n, []byte("ad"))
+	assert.Equal(t, reference.Encrypt(nil, decryptedPacket), ciphertext)
+	assert.NotEqual(t, reference.Tag(), tag)
+
+	d := variant.fork().newDecrypter(n, []byte("ad"))
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, d.VerifyArray(tag))
+}
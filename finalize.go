@@ -0,0 +1,32 @@
+package uncheckedgcm
+
+import "errors"
+
+// ErrFinalized is returned by Decrypt, and Encrypt panics with it, once the
+// tag has been computed. Tag consumes the running GHASH, so data processed
+// afterwards could never be authenticated correctly.
+var ErrFinalized = errors.New("gcm: data processed after the tag was computed")
+
+// finalize records tag as the message's tag. Later calls to Tag return it
+// without touching the consumed GHASH state. No more data can be processed,
+// so the buffered keystream, including any unused leftover, is wiped.
+func (g *gcm) finalize(tag [gcmTagSize]byte) {
+	g.finalTag = tag
+	g.finalized = true
+
+	clear(g.extraMask)
+	clear(g.mask[:])
+	g.extraMask = nil
+}
+
+// TagInto writes the tag to dst, following the same finalization rules as
+// Tag. It lets callers keep the tag in storage they reuse across messages.
+func (g *Encrypter) TagInto(dst *[gcmTagSize]byte) {
+	*dst = g.Tag()
+}
+
+// TagInto writes the tag to dst, following the same finalization rules as
+// Tag.
+func (g *Decrypter) TagInto(dst *[gcmTagSize]byte) {
+	*dst = g.Tag()
+}
@@ -0,0 +1,99 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptAfterVerify(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, make([]byte, 40))
+	tag := e.Tag()
+
+	d := newGCMDecrypter(block, nonce, nil)
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, d.VerifyArray(tag))
+
+	// Verifying again gives the same answer rather than hashing the
+	// consumed state a second time.
+	assert.Nil(t, d.VerifyArray(tag))
+	assert.Equal(t, tag, d.PeekTag())
+
+	_, err = d.Decrypt(nil, []byte("trailing"))
+	assert.ErrorIs(t, err, ErrFinalized)
+	assert.Nil(t, d.VerifyArray(tag))
+}
+
+func TestEncryptAfterTag(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	e.Encrypt(nil, make([]byte, 40))
+	tag := e.Tag()
+	assert.Equal(t, tag, e.Tag())
+
+	assert.PanicsWithValue(t, ErrFinalized, func() { e.Encrypt(nil, []byte("more")) })
+}
+
+func TestFinalizeWipesKeystream(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var zero [maxCounterBatch * gcmBlockSize]byte
+
+	e := newGCMEncrypter(block, nonce, nil)
+	e.Encrypt(nil, decryptedPacket)
+	leftover := e.extraMask
+	assert.NotEmpty(t, leftover)
+
+	e.Tag()
+	assert.Empty(t, e.extraMask)
+	assert.Equal(t, make([]byte, len(leftover)), leftover)
+	assert.Equal(t, zero, e.mask)
+
+	// Keystream from a bulk block lives outside mask.
+	d := newGCMDecrypter(&bulkBlock{Block: block}, nonce, nil)
+	_, err = d.Decrypt(nil, encryptedPacket)
+	assert.Nil(t, err)
+	leftover = d.extraMask
+	assert.NotEmpty(t, leftover)
+
+	d.Tag()
+	assert.Empty(t, d.extraMask)
+	assert.Equal(t, make([]byte, len(leftover)), leftover)
+}
+
+func TestTagInto(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	d := newGCMDecrypter(block, nonce, nil)
+	buf := make([]byte, len(decryptedPacket))
+
+	var encTag, decTag [gcmTagSize]byte
+	allocs := testing.AllocsPerRun(100, func() {
+		e.Reset(nonce, nil)
+		e.Encrypt(buf[:0], decryptedPacket)
+		e.TagInto(&encTag)
+
+		d.Reset(nonce, nil)
+		d.Decrypt(buf[:0], encryptedPacket)
+		d.TagInto(&decTag)
+	})
+	assert.Equal(t, 0.0, allocs)
+	assert.Equal(t, encTag, decTag)
+
+	// Finalized instances keep returning the same tag.
+	var again [gcmTagSize]byte
+	e.TagInto(&again)
+	assert.Equal(t, encTag, again)
+	assert.Equal(t, encTag, e.Tag())
+}
@@ -36,41 +36,74 @@ import (
 )
 
 const (
-	gcmNonceSize = 16
-	gcmBlockSize = 16
-	gcmTagSize   = 16
+	gcmNonceSize         = 16
+	gcmStandardNonceSize = 12
+	gcmBlockSize         = 16
+	gcmTagSize           = 16
 )
 
-var errOpen = errors.New("gcm: message authentication failed")
+var (
+	// ErrOpen is returned when a tag does not match the processed data.
+	ErrOpen = errors.New("gcm: message authentication failed")
 
-var gcmReductionTable = []uint16{
-	0x0000, 0x1c20, 0x3840, 0x2460, 0x7080, 0x6ca0, 0x48c0, 0x54e0,
-	0xe100, 0xfd20, 0xd940, 0xc560, 0x9180, 0x8da0, 0xa9c0, 0xb5e0,
-}
+	errNonceSize = errors.New("gcm: incorrect nonce length given to GCM")
+)
 
 type gcmFieldElement struct {
 	low, high uint64
 }
 
 type gcm struct {
-	cipher       cipher.Block
-	tagMask      [gcmBlockSize]byte
-	counter      [gcmBlockSize]byte
-	extraMask    []byte
-	ghash        gcmFieldElement
-	productTable [16]gcmFieldElement
+	cipher          cipher.Block
+	tagMask         [gcmBlockSize]byte
+	counter         [gcmBlockSize]byte
+	initialCounter  [gcmBlockSize]byte
+	extraMask       []byte
+	mask            [maxCounterBatch * gcmBlockSize]byte
+	counterBatch    int
+	ghash           gcmFieldElement
+	ghashTail       [gcmBlockSize]byte
+	ghashTailNb     int
+	productTable    [16]gcmFieldElement
+	field           *gcmField
+	reversed        reversedAD
+	lengthBlock     LengthBlockFunc
+	deferAD         bool
+	lazyAD          []byte
+	ghashBlocks     uint64
+	discarded       bool
+	finalized       bool
+	adDone          bool
+	constantTimePad bool
+	macPlaintext    bool
+	interleaved     bool
+	interleavedAD   adAccumulator
+	checkpointEvery uint64
+	onCheckpoint    func(Checkpoint)
+	finalTag        [gcmTagSize]byte
 }
 
-type gcmEncrypter struct {
+// Encrypter encrypts one message with GCM, producing ciphertext as it goes.
+// The tag for the ciphertext produced so far is available at any point from
+// Tag or PeekTag.
+type Encrypter struct {
 	*gcm
 	plaintextNb      uint64
 	additionalDataNb uint64
 }
 
-type gcmDecrypter struct {
+// Decrypter decrypts one GCM message without first checking its tag, so the
+// plaintext it returns is unauthenticated until Verify succeeds.
+type Decrypter struct {
 	*gcm
 	ciphertextNb     uint64
 	additionalDataNb uint64
+	observer         Observer
+	expectedNb       uint64
+	expectLength     bool
+	boundNb          uint64
+	bindLength       bool
+	expectedTag      []byte
 }
 
 func anyOverlap(x, y []byte) bool {
@@ -86,6 +119,11 @@ func inexactOverlap(x, y []byte) bool {
 	return anyOverlap(x, y)
 }
 
+// sliceForAppend takes a slice and a requested number of bytes. It returns a
+// slice with the contents of the given slice followed by that many bytes and
+// a second slice that aliases into it and contains only the extra bytes. If
+// the original slice has sufficient capacity then no allocation is performed.
+// Like append, a nil slice extended by zero bytes stays nil.
 func sliceForAppend(in []byte, n int) (head, tail []byte) {
 	if total := len(in) + n; cap(in) >= total {
 		head = in[:total]
@@ -112,34 +150,47 @@ func gcmAdd(x, y *gcmFieldElement) gcmFieldElement {
 	return gcmFieldElement{x.low ^ y.low, x.high ^ y.high}
 }
 
-func gcmDouble(x *gcmFieldElement) (double gcmFieldElement) {
-	msbSet := x.high&1 == 1
-
-	double.high = x.high >> 1
-	double.high |= x.low << 63
-	double.low = x.low >> 1
+func gcmDouble(x *gcmFieldElement) gcmFieldElement {
+	return standardField.double(x)
+}
 
-	if msbSet {
-		double.low ^= 0xe100000000000000
+// Validate reports whether nonce and additionalData are acceptable to the
+// encrypter and decrypter constructors. If it returns nil, constructing with
+// the same arguments will not panic.
+//
+// Any non-empty nonce is accepted. 16-byte nonces are this package's
+// convention; 12-byte nonces use the standard GCM counter derivation.
+func Validate(nonce, additionalData []byte) error {
+	if len(nonce) == 0 {
+		return errNonceSize
 	}
-
-	return
+	return nil
 }
 
-func newGCMEncrypter(cipher cipher.Block, nonce, additionalData []byte) *gcmEncrypter {
-	if len(nonce) != gcmNonceSize {
-		panic("incorrect nonce length given to GCM")
-	}
+// gcmMul returns the product of x and y in GCM's field. Unlike mul it does
+// not need a product table, so it can multiply by values other than H.
+func gcmMul(x, y *gcmFieldElement) gcmFieldElement {
+	return standardField.mul(x, y)
+}
 
-	var key [gcmBlockSize]byte
-	cipher.Encrypt(key[:], key[:])
+// newGCM returns a gcm holding the product table for cipher's hash subkey.
+// It must be started with start before use.
+//
+// cipher must have a 16-byte block size. Its Encrypt method is always given
+// distinct, non-overlapping src and dst slices, so block implementations
+// that do not support in-place operation may be used.
+func newGCM(cipher cipher.Block) *gcm {
+	var zero, key [gcmBlockSize]byte
+	cipher.Encrypt(key[:], zero[:])
+
+	return newGCMWithSubkey(cipher, key)
+}
 
-	g := &gcmEncrypter{
-		gcm: &gcm{
-			cipher: cipher,
-		},
-		plaintextNb:      0,
-		additionalDataNb: uint64(len(additionalData)),
+// setSubkey fills the product table with multiples of the hash subkey H.
+// A gcm with no field set uses the standard GCM field.
+func (g *gcm) setSubkey(key [gcmBlockSize]byte) {
+	if g.field == nil {
+		g.field = standardField
 	}
 
 	x := gcmFieldElement{
@@ -149,124 +200,306 @@ func newGCMEncrypter(cipher cipher.Block, nonce, additionalData []byte) *gcmEncr
 	g.productTable[reverseBits(1)] = x
 
 	for i := 2; i < 16; i += 2 {
-		g.productTable[reverseBits(i)] = gcmDouble(&g.productTable[reverseBits(i/2)])
+		g.productTable[reverseBits(i)] = g.field.double(&g.productTable[reverseBits(i/2)])
 		g.productTable[reverseBits(i+1)] = gcmAdd(&g.productTable[reverseBits(i)], &x)
 	}
+}
 
-	g.update(&g.ghash, additionalData)
+// start begins a message under nonce, authenticating additionalData.
+func (g *gcm) start(nonce, additionalData []byte) {
+	g.startAD(additionalData)
+	g.setNonce(nonce)
+}
+
+// startAD authenticates the additional data given at construction, or
+// stores it for later if hashing is deferred.
+func (g *gcm) startAD(additionalData []byte) {
+	switch {
+	case g.interleaved:
+		g.interleavedAD.update(g, additionalData)
+	case g.deferAD:
+		g.lazyAD = append([]byte{}, additionalData...)
+	default:
+		g.updateStream(additionalData)
+	}
+}
 
+// setNonce derives J0 from nonce and starts the counter.
+func (g *gcm) setNonce(nonce []byte) {
 	g.deriveCounter(nonce)
+	g.startCounter()
+}
+
+// startCounter computes the tag mask from J0 in counter and advances the
+// counter to the first keystream block.
+func (g *gcm) startCounter() {
 	g.cipher.Encrypt(g.tagMask[:], g.counter[:])
 	gcmInc32(&g.counter)
+	g.initialCounter = g.counter
+}
 
-	return g
+// InitialCounter returns the counter block used for the first block of
+// keystream, one past the block used to mask the tag.
+func (g *gcm) InitialCounter() [gcmBlockSize]byte {
+	return g.initialCounter
 }
 
-func newGCMDecrypter(cipher cipher.Block, nonce, additionalData []byte) *gcmDecrypter {
-	if len(nonce) != gcmNonceSize {
-		panic("gcm: incorrect nonce length given to GCM")
-	}
+// FinalCounter returns the counter block that will produce the next block of
+// keystream. The low 32 bits of FinalCounter minus those of InitialCounter
+// is the number of keystream blocks generated so far.
+func (g *gcm) FinalCounter() [gcmBlockSize]byte {
+	return g.counter
+}
 
-	var key [gcmBlockSize]byte
-	cipher.Encrypt(key[:], key[:])
+// fork returns a copy of g that can be started independently. g must not
+// have been started, so only the cipher and product table are shared.
+func (g *gcm) fork() *gcm {
+	f := *g
+	return &f
+}
 
-	g := &gcmDecrypter{
-		gcm: &gcm{
-			cipher: cipher,
-		},
-		ciphertextNb:     0,
-		additionalDataNb: uint64(len(additionalData)),
-	}
+func newGCMEncrypter(cipher cipher.Block, nonce, additionalData []byte) *Encrypter {
+	return newGCM(cipher).newEncrypter(nonce, additionalData)
+}
 
-	x := gcmFieldElement{
-		binary.BigEndian.Uint64(key[:8]),
-		binary.BigEndian.Uint64(key[8:]),
+func newGCMDecrypter(cipher cipher.Block, nonce, additionalData []byte) *Decrypter {
+	return newGCM(cipher).newDecrypter(nonce, additionalData)
+}
+
+// newEncrypter starts an encrypter on g, which must not have been started.
+func (g *gcm) newEncrypter(nonce, additionalData []byte) *Encrypter {
+	if err := Validate(nonce, additionalData); err != nil {
+		panic(err)
 	}
-	g.productTable[reverseBits(1)] = x
 
-	for i := 2; i < 16; i += 2 {
-		g.productTable[reverseBits(i)] = gcmDouble(&g.productTable[reverseBits(i/2)])
-		g.productTable[reverseBits(i+1)] = gcmAdd(&g.productTable[reverseBits(i)], &x)
+	e := &Encrypter{
+		gcm:              g,
+		additionalDataNb: uint64(len(additionalData)),
 	}
+	e.start(nonce, additionalData)
 
-	g.update(&g.ghash, additionalData)
+	return e
+}
 
-	g.deriveCounter(nonce)
-	g.cipher.Encrypt(g.tagMask[:], g.counter[:])
-	gcmInc32(&g.counter)
+// newDecrypter starts a decrypter on g, which must not have been started.
+func (g *gcm) newDecrypter(nonce, additionalData []byte) *Decrypter {
+	if err := Validate(nonce, additionalData); err != nil {
+		panic(err)
+	}
+
+	d := &Decrypter{
+		gcm:              g,
+		additionalDataNb: uint64(len(additionalData)),
+	}
+	d.start(nonce, additionalData)
 
-	return g
+	return d
 }
 
 // Encrypt encrypts the plaintext and returns the resulting ciphertext.
-func (g *gcmEncrypter) Encrypt(dst, plaintext []byte) []byte {
+func (g *Encrypter) Encrypt(dst, plaintext []byte) []byte {
+	g.mustBeLive()
+	if g.finalized {
+		panic(ErrFinalized)
+	}
+
 	ret, out := sliceForAppend(dst, len(plaintext))
 	if inexactOverlap(out, plaintext) {
 		panic("gcm: invalid buffer overlap")
 	}
+	if err := g.checkKeystream(len(plaintext)); err != nil {
+		panic(err)
+	}
 
-	g.update(&g.ghash, plaintext)
-	g.plaintextNb += uint64(len(plaintext))
-
-	g.counterCrypt(out, plaintext, &g.counter)
+	g.endAD()
+	if g.onCheckpoint == nil {
+		g.encrypt(out, plaintext)
+		return ret
+	}
 
+	for i := 0; i < len(plaintext); {
+		j := i + g.untilCheckpoint(g.plaintextNb, len(plaintext)-i)
+		g.encrypt(out[i:j], plaintext[i:j])
+		g.checkpoint(g.additionalDataNb, g.plaintextNb)
+		i = j
+	}
 	return ret
 }
 
-// Tag returns the GCM tag for the plaintext processed so far.
-func (g *gcmEncrypter) Tag() [gcmTagSize]byte {
-	var tag [gcmTagSize]byte
-
-	g.ghash.low ^= g.additionalDataNb * 8
-	g.ghash.high ^= g.plaintextNb * 8
-	g.mul(&g.ghash)
+func (g *Encrypter) encrypt(out, plaintext []byte) {
+	switch {
+	case g.macPlaintext:
+		// plaintext must be hashed before it is encrypted, since out
+		// may alias it.
+		g.updateStream(plaintext)
+		g.counterCrypt(out, plaintext, &g.counter)
+	case len(plaintext) >= pipelineThreshold:
+		g.encryptPipelined(out, plaintext)
+	default:
+		g.counterCrypt(out, plaintext, &g.counter)
+		g.updateStream(out)
+	}
+	g.plaintextNb += uint64(len(plaintext))
+}
 
-	binary.BigEndian.PutUint64(tag[:], g.ghash.low)
-	binary.BigEndian.PutUint64(tag[8:], g.ghash.high)
+// Tag returns the GCM tag for the plaintext processed so far and finalizes
+// the encrypter: calling Encrypt afterwards panics with ErrFinalized, and
+// calling Tag again returns the same tag.
+func (g *Encrypter) Tag() [gcmTagSize]byte {
+	g.mustBeLive()
+	if !g.finalized {
+		g.flush()
+		g.foldLazyAD()
+		g.finalize(g.tag(&g.ghash, g.additionalDataNb, g.plaintextNb))
+	}
+	return g.finalTag
+}
 
-	subtle.XORBytes(tag[:], tag[:], g.tagMask[:])
-	return tag
+// PeekTag returns the GCM tag for the plaintext processed so far without
+// finalizing the encrypter, so encryption can continue afterwards.
+func (g *Encrypter) PeekTag() [gcmTagSize]byte {
+	g.mustBeLive()
+	if g.finalized {
+		return g.finalTag
+	}
+	ghash := g.pending()
+	return g.tag(&ghash, g.additionalDataNb, g.plaintextNb)
 }
 
 // Verify returns nil if the tag matches the correct GCM tag for the ciphertext processed so far.
-func (g *gcmDecrypter) Verify(tag []byte) error {
+// The comparison is constant time, so a mismatching tag takes as long to
+// reject whichever byte it differs in.
+func (g *Decrypter) Verify(tag []byte) error {
+	if g.discarded {
+		return ErrDiscarded
+	}
+
+	err := g.verify(tag)
+	if g.observer != nil {
+		g.observer.OnVerify(err == nil, g.ciphertextNb)
+	}
+	return err
+}
+
+// VerifyArray is like Verify but takes the tag as a fixed-size array.
+func (g *Decrypter) VerifyArray(tag [gcmTagSize]byte) error {
+	return g.Verify(tag[:])
+}
+
+func (g *Decrypter) verify(tag []byte) error {
+	if err := g.checkLength(); err != nil {
+		return err
+	}
+
 	if len(tag) != gcmTagSize {
-		return errOpen
+		return ErrOpen
 	}
 
 	expected := g.Tag()
 	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
-		return errOpen
+		return ErrOpen
 	}
 
 	return nil
 }
 
 // Decrypt decrypts the ciphertext and returns the resulting plaintext.
-func (g *gcmDecrypter) Decrypt(dst, ciphertext []byte) ([]byte, error) {
+//
+// The work Decrypt does depends only on the length of the ciphertext and how
+// it is split across calls: every call generates keystream for and hashes
+// every byte it is given, and nothing branches on the ciphertext's or
+// plaintext's contents. A tag mismatch is only detected by Verify. GHASH uses
+// a 4-bit table indexed by the hash state, so, like other table-based
+// software GHASH, it is not hardened against cache-timing attacks.
+func (g *Decrypter) Decrypt(dst, ciphertext []byte) ([]byte, error) {
+	if g.discarded {
+		return nil, ErrDiscarded
+	}
+	if g.finalized {
+		return nil, ErrFinalized
+	}
+
 	ret, out := sliceForAppend(dst, len(ciphertext))
 	if inexactOverlap(out, ciphertext) {
 		panic("gcm: invalid buffer overlap")
 	}
+	if err := g.checkKeystream(len(ciphertext)); err != nil {
+		return nil, err
+	}
+
+	g.endAD()
+	if g.onCheckpoint == nil {
+		g.decrypt(out, ciphertext)
+		return ret, nil
+	}
+
+	for i := 0; i < len(ciphertext); {
+		j := i + g.untilCheckpoint(g.ciphertextNb, len(ciphertext)-i)
+		g.decrypt(out[i:j], ciphertext[i:j])
+		g.checkpoint(g.additionalDataNb, g.ciphertextNb)
+		i = j
+	}
+	return ret, nil
+}
 
-	g.update(&g.ghash, ciphertext)
+func (g *Decrypter) decrypt(out, ciphertext []byte) {
+	if !g.macPlaintext {
+		g.updateStream(ciphertext)
+	}
 	g.ciphertextNb += uint64(len(ciphertext))
 
 	g.counterCrypt(out, ciphertext, &g.counter)
 
-	return ret, nil
+	if g.macPlaintext {
+		g.updateStream(out)
+	}
+}
+
+// Tag returns the GCM tag for the ciphertext processed so far and finalizes
+// the decrypter: calling Decrypt afterwards returns ErrFinalized, and calling
+// Tag or Verify again uses the same tag.
+func (g *Decrypter) Tag() [gcmTagSize]byte {
+	g.mustBeLive()
+	if !g.finalized {
+		g.flush()
+		g.foldLazyAD()
+		g.finalize(g.tag(&g.ghash, g.additionalDataNb, g.ciphertextNb))
+	}
+	return g.finalTag
+}
+
+// PeekTag returns the GCM tag for the ciphertext processed so far without
+// finalizing the decrypter, so decryption can continue afterwards.
+func (g *Decrypter) PeekTag() [gcmTagSize]byte {
+	g.mustBeLive()
+	if g.finalized {
+		return g.finalTag
+	}
+	ghash := g.pending()
+	return g.tag(&ghash, g.additionalDataNb, g.ciphertextNb)
 }
 
-// Tag returns the GCM tag for the ciphertext processed so far.
-func (g *gcmDecrypter) Tag() [gcmTagSize]byte {
+// tag folds the length block into y and returns the masked tag.
+func (g *gcm) tag(y *gcmFieldElement, additionalDataNb, dataNb uint64) [gcmTagSize]byte {
+	return g.tagBits(y, additionalDataNb*8, dataNb*8)
+}
+
+// tagBits is tag with the lengths given in bits.
+func (g *gcm) tagBits(y *gcmFieldElement, additionalDataBits, dataBits uint64) [gcmTagSize]byte {
 	var tag [gcmTagSize]byte
 
-	g.ghash.low ^= g.additionalDataNb * 8
-	g.ghash.high ^= g.ciphertextNb * 8
-	g.mul(&g.ghash)
+	if g.lengthBlock != nil {
+		block := g.lengthBlock(additionalDataBits, dataBits)
+		y.low ^= binary.BigEndian.Uint64(block[:8])
+		y.high ^= binary.BigEndian.Uint64(block[8:])
+	} else {
+		y.low ^= additionalDataBits
+		y.high ^= dataBits
+	}
+	g.mul(y)
 
-	binary.BigEndian.PutUint64(tag[:], g.ghash.low)
-	binary.BigEndian.PutUint64(tag[8:], g.ghash.high)
+	binary.BigEndian.PutUint64(tag[:], y.low)
+	binary.BigEndian.PutUint64(tag[8:], y.high)
 
 	subtle.XORBytes(tag[:], tag[:], g.tagMask[:])
 	return tag
@@ -288,7 +521,7 @@ func (g *gcm) mul(y *gcmFieldElement) {
 			z.high >>= 4
 			z.high |= z.low << 60
 			z.low >>= 4
-			z.low ^= uint64(gcmReductionTable[msw]) << 48
+			z.low ^= g.field.table[msw]
 
 			// the values in |table| are ordered for
 			// little-endian bit positions. See the comment
@@ -305,6 +538,10 @@ func (g *gcm) mul(y *gcmFieldElement) {
 }
 
 func (g *gcm) updateBlocks(y *gcmFieldElement, blocks []byte) {
+	if y == &g.ghash {
+		g.ghashBlocks += uint64(len(blocks) / gcmBlockSize)
+	}
+
 	for len(blocks) > 0 {
 		y.low ^= binary.BigEndian.Uint64(blocks)
 		y.high ^= binary.BigEndian.Uint64(blocks[8:])
@@ -319,22 +556,121 @@ func (g *gcm) update(y *gcmFieldElement, data []byte) {
 
 	if len(data) != fullBlocks {
 		var partialBlock [gcmBlockSize]byte
-		copy(partialBlock[:], data[fullBlocks:])
+		if g.constantTimePad {
+			padConstantTime(&partialBlock, data[fullBlocks:])
+		} else {
+			copy(partialBlock[:], data[fullBlocks:])
+		}
 		g.updateBlocks(y, partialBlock[:])
 	}
 }
 
+// updateStream adds data to the running GHASH. An unaligned tail is held
+// back in ghashTail until the next call fills it, so the result does not
+// depend on how the data is split across calls and at most one block is ever
+// buffered.
+func (g *gcm) updateStream(data []byte) {
+	if g.ghashTailNb > 0 {
+		n := copy(g.ghashTail[g.ghashTailNb:], data)
+		g.ghashTailNb += n
+		data = data[n:]
+
+		if g.ghashTailNb < gcmBlockSize {
+			return
+		}
+		g.updateBlocks(&g.ghash, g.ghashTail[:])
+		g.ghashTailNb = 0
+	}
+
+	fullBlocks := (len(data) >> 4) << 4
+	g.updateBlocks(&g.ghash, data[:fullBlocks])
+	g.ghashTailNb = copy(g.ghashTail[:], data[fullBlocks:])
+}
+
+// endAD pads the additional data to a block boundary before the first
+// plaintext or ciphertext is hashed.
+func (g *gcm) endAD() {
+	if !g.adDone {
+		g.flush()
+		g.adDone = true
+	}
+}
+
+// flush zero-pads and hashes any tail held back by updateStream.
+func (g *gcm) flush() {
+	if g.ghashTailNb > 0 {
+		if g.constantTimePad {
+			padConstantTime(&g.ghashTail, g.ghashTail[:g.ghashTailNb])
+		} else {
+			clear(g.ghashTail[g.ghashTailNb:])
+		}
+		g.updateBlocks(&g.ghash, g.ghashTail[:])
+		g.ghashTailNb = 0
+	}
+}
+
+// pending returns the GHASH state as Tag would hash it, with any tail and
+// deferred additional data folded in, without modifying g.
+func (g *gcm) pending() gcmFieldElement {
+	y := g.ghash
+	blocks := g.ghashBlocks
+	if g.ghashTailNb > 0 {
+		g.update(&y, g.ghashTail[:g.ghashTailNb])
+		blocks++
+	}
+	g.foldAD(&y, g.lazyAD, blocks)
+	g.foldInterleavedAD(&y, blocks)
+	return y
+}
+
 func (g *gcm) deriveCounter(nonce []byte) {
-	var y gcmFieldElement
-	g.update(&y, nonce[:])
-	y.high ^= uint64(len(nonce)) * 8
+	g.deriveCounterFromState(gcmFieldElement{}, 0, nonce)
+}
+
+// deriveCounterFromState derives J0 for a nonce whose first prefixLen bytes,
+// a multiple of the block size, have already been hashed into partialGHASH.
+func (g *gcm) deriveCounterFromState(partialGHASH gcmFieldElement, prefixLen int, remainingNonce []byte) {
+	if prefixLen == 0 && len(remainingNonce) == gcmStandardNonceSize {
+		g.counter = [gcmBlockSize]byte{}
+		copy(g.counter[:], remainingNonce)
+		g.counter[gcmBlockSize-1] = 1
+		return
+	}
+
+	y := partialGHASH
+	g.update(&y, remainingNonce)
+	y.high ^= uint64(prefixLen+len(remainingNonce)) * 8
 	g.mul(&y)
 	binary.BigEndian.PutUint64(g.counter[:8], y.low)
 	binary.BigEndian.PutUint64(g.counter[8:], y.high)
 }
 
+// Reserve precomputes keystream for at least the next n bytes so that
+// encrypting or decrypting them is a plain XOR with no further block cipher
+// calls. The keystream is held in memory until it is used.
+func (g *gcm) Reserve(n int) {
+	need := n - len(g.extraMask)
+	if need <= 0 {
+		return
+	}
+	if err := g.checkKeystream(n); err != nil {
+		panic(err)
+	}
+	blocks := (need + gcmBlockSize - 1) / gcmBlockSize
+
+	keystream := make([]byte, len(g.extraMask), len(g.extraMask)+blocks*gcmBlockSize)
+	copy(keystream, g.extraMask)
+
+	keystream = keystream[:len(keystream)+blocks*gcmBlockSize]
+	g.keystream(keystream[len(g.extraMask):], &g.counter)
+
+	g.extraMask = keystream
+}
+
 func (g *gcm) counterCrypt(out, in []byte, counter *[gcmBlockSize]byte) {
-	var mask [gcmBlockSize]byte
+	// The unused end of the mask is kept in extraMask, so it lives in g
+	// rather than on the stack to keep streaming calls allocation-free.
+	mask := &g.mask
 
 	if len(g.extraMask) > 0 {
 		n := subtle.XORBytes(out, in, g.extraMask)
@@ -343,13 +679,30 @@ func (g *gcm) counterCrypt(out, in []byte, counter *[gcmBlockSize]byte) {
 		g.extraMask = g.extraMask[n:]
 	}
 
+	if _, ok := g.cipher.(BulkBlock); ok && len(in) > 0 {
+		// Generate all the keystream in one call to the block.
+		keystream := make([]byte, (len(in)+gcmBlockSize-1)/gcmBlockSize*gcmBlockSize)
+		g.keystream(keystream, counter)
+
+		n := subtle.XORBytes(out, in, keystream)
+		g.extraMask = keystream[n:]
+		return
+	}
+
+	batch := g.counterBatch
+	if batch == 0 {
+		batch = maxCounterBatch
+	}
+
 	for len(in) > 0 {
-		g.cipher.Encrypt(mask[:], counter[:])
-		gcmInc32(counter)
+		// Never generate more keystream than in needs, so the counter
+		// advances the same whatever the batch size.
+		blocks := min(batch, (len(in)+gcmBlockSize-1)/gcmBlockSize)
+		g.keystream(mask[:blocks*gcmBlockSize], counter)
 
-		n := subtle.XORBytes(out, in, mask[:])
+		n := subtle.XORBytes(out, in, mask[:blocks*gcmBlockSize])
 		out = out[n:]
 		in = in[n:]
-		g.extraMask = mask[n:]
+		g.extraMask = mask[n : blocks*gcmBlockSize]
 	}
 }
@@ -1,7 +1,12 @@
 package uncheckedgcm
 
 import (
+	"bytes"
 	"crypto/aes"
+	"crypto/cipher"
+	"crypto/subtle"
+	"encoding/binary"
+	"math/rand"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -36,9 +41,13 @@ func TestEncryptTag(t *testing.T) {
 
 	gcm := newGCMEncrypter(block, nonce, nil)
 
-	ciphertext := []byte{0, 0, 0, 0}
-	ciphertext = gcm.Encrypt(ciphertext[:0], ciphertext)
-	assert.Nil(t, err)
+	// The tag covers the ciphertext, so encrypt the keystream to get the
+	// all-zero ciphertext that the decrypt tests authenticate.
+	plaintext := make([]byte, 4)
+	subtle.XORBytes(plaintext, decryptedPacket[:4], encryptedPacket[:4])
+
+	ciphertext := gcm.Encrypt(nil, plaintext)
+	assert.Equal(t, []byte{0, 0, 0, 0}, ciphertext)
 
 	assert.Equal(t, tag, gcm.Tag())
 }
@@ -84,3 +93,549 @@ func TestDecryptVerifyTag(t *testing.T) {
 	err = gcm.Verify(tag[:])
 	assert.Nil(t, err)
 }
+
+func TestValidate(t *testing.T) {
+	assert.Nil(t, Validate(nonce, nil))
+	assert.Nil(t, Validate(nonce, []byte("additional data")))
+
+	assert.Nil(t, Validate(nonce[:12], nil))
+	assert.Nil(t, Validate(append(nonce, 0), nil))
+
+	assert.ErrorIs(t, Validate(nil, nil), errNonceSize)
+	assert.ErrorIs(t, Validate([]byte{}, nil), errNonceSize)
+}
+
+func TestConstructorPanicsOnInvalidNonce(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	assert.PanicsWithValue(t, errNonceSize, func() { newGCMEncrypter(block, nil, nil) })
+	assert.PanicsWithValue(t, errNonceSize, func() { newGCMDecrypter(block, nil, nil) })
+}
+
+func TestEncryptPeekTag(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	partial := newGCMEncrypter(block, nonce, nil)
+	partial.Encrypt(nil, decryptedPacket[:16])
+
+	full := newGCMEncrypter(block, nonce, nil)
+	full.Encrypt(nil, decryptedPacket[:16])
+
+	peeked := full.PeekTag()
+	assert.Equal(t, partial.Tag(), peeked)
+	assert.Equal(t, peeked, full.PeekTag())
+
+	full.Encrypt(nil, decryptedPacket[16:])
+
+	whole := newGCMEncrypter(block, nonce, nil)
+	whole.Encrypt(nil, decryptedPacket[:16])
+	whole.Encrypt(nil, decryptedPacket[16:])
+
+	assert.Equal(t, whole.Tag(), full.Tag())
+}
+
+func TestDecryptPeekTag(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	gcm := newGCMDecrypter(block, nonce, nil)
+
+	ciphertext := []byte{0, 0, 0, 0}
+	_, err = gcm.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+
+	assert.Equal(t, tag, gcm.PeekTag())
+	assert.Nil(t, gcm.Verify(tag[:]))
+}
+
+// counterCrypt advances its slices by the count subtle.XORBytes returns and
+// keeps the unused tail of the mask in extraMask. Pin the behaviour it relies
+// on so that a change in the standard library fails here rather than
+// silently corrupting ciphertext.
+func TestXORBytesChunking(t *testing.T) {
+	mask := make([]byte, gcmBlockSize)
+	for i := range mask {
+		mask[i] = byte(i + 1)
+	}
+
+	dst := bytes.Repeat([]byte{0xff}, gcmBlockSize)
+	n := subtle.XORBytes(dst, []byte{0x10, 0x20, 0x30}, mask)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, []byte{0x11, 0x22, 0x33}, dst[:3])
+	assert.Equal(t, bytes.Repeat([]byte{0xff}, gcmBlockSize-3), dst[3:])
+
+	n = subtle.XORBytes(dst, bytes.Repeat([]byte{0xff}, gcmBlockSize), mask[11:])
+	assert.Equal(t, 5, n)
+
+	n = subtle.XORBytes(dst[:0], nil, mask)
+	assert.Equal(t, 0, n)
+
+	assert.Panics(t, func() { subtle.XORBytes(dst[:2], mask, mask) })
+}
+
+func TestCounterCryptSplits(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 3*gcmBlockSize+5)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	want := make([]byte, len(plaintext))
+	g := newGCMEncrypter(block, nonce, nil)
+	g.counterCrypt(want, plaintext, &g.counter)
+
+	for i := 0; i <= len(plaintext); i++ {
+		for j := i; j <= len(plaintext); j++ {
+			got := make([]byte, len(plaintext))
+
+			g := newGCMEncrypter(block, nonce, nil)
+			g.counterCrypt(got[:i], plaintext[:i], &g.counter)
+			g.counterCrypt(got[i:j], plaintext[i:j], &g.counter)
+			g.counterCrypt(got[j:], plaintext[j:], &g.counter)
+
+			assert.Equal(t, want, got, "split at %d and %d", i, j)
+		}
+	}
+}
+
+func TestDecryptVerifyArray(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, candidate := range [][gcmTagSize]byte{tag, {}} {
+		a := newGCMDecrypter(block, nonce, nil)
+		_, err = a.Decrypt(nil, []byte{0, 0, 0, 0})
+		assert.Nil(t, err)
+
+		b := newGCMDecrypter(block, nonce, nil)
+		_, err = b.Decrypt(nil, []byte{0, 0, 0, 0})
+		assert.Nil(t, err)
+
+		assert.Equal(t, a.Verify(candidate[:]), b.VerifyArray(candidate))
+	}
+}
+
+func TestDecryptVerifyArrayAllocs(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	gcm := newGCMDecrypter(block, nonce, nil)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		gcm.VerifyArray(tag)
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestEncryptEmpty(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	gcm := newGCMEncrypter(block, nonce, nil)
+
+	assert.Nil(t, gcm.Encrypt(nil, nil))
+	assert.Nil(t, gcm.Encrypt(nil, []byte{}))
+
+	ret := gcm.Encrypt([]byte{}, nil)
+	assert.NotNil(t, ret)
+	assert.Empty(t, ret)
+
+	prefix := []byte{1, 2, 3}
+	assert.Equal(t, prefix, gcm.Encrypt(prefix, nil))
+
+	ret = gcm.Encrypt(nil, decryptedPacket[:4])
+	assert.Equal(t, encryptedPacket[:4], ret)
+
+	ret = gcm.Encrypt([]byte{}, decryptedPacket[4:])
+	assert.Equal(t, encryptedPacket[4:], ret)
+
+	ret = gcm.Encrypt(prefix, nil)
+	assert.Equal(t, prefix, ret)
+
+	// Empty calls must not disturb the keystream or the tag.
+	plain := newGCMEncrypter(block, nonce, nil)
+	plain.Encrypt(nil, decryptedPacket[:4])
+	plain.Encrypt(nil, decryptedPacket[4:])
+	assert.Equal(t, plain.Tag(), gcm.Tag())
+}
+
+func TestDecryptEmpty(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	gcm := newGCMDecrypter(block, nonce, nil)
+
+	ret, err := gcm.Decrypt(nil, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, ret)
+
+	ret, err = gcm.Decrypt([]byte{}, nil)
+	assert.Nil(t, err)
+	assert.NotNil(t, ret)
+	assert.Empty(t, ret)
+
+	ret, err = gcm.Decrypt(nil, []byte{0, 0, 0, 0})
+	assert.Nil(t, err)
+	assert.Len(t, ret, 4)
+
+	assert.Nil(t, gcm.Verify(tag[:]))
+}
+
+func TestCounterCryptEmpty(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	gcm := newGCMEncrypter(block, nonce, nil)
+	gcm.Encrypt(nil, decryptedPacket[:5])
+
+	extraMask := gcm.extraMask
+	counter := gcm.counter
+
+	gcm.counterCrypt(nil, nil, &gcm.counter)
+	gcm.counterCrypt([]byte{}, []byte{}, &gcm.counter)
+
+	assert.Equal(t, extraMask, gcm.extraMask)
+	assert.Equal(t, counter, gcm.counter)
+}
+
+func TestEncryptSingleBytesBounded(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	const n = 1000000
+
+	plaintext := make([]byte, n)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	whole := newGCMEncrypter(block, nonce, nil)
+	want := whole.Encrypt(nil, plaintext)
+
+	encrypters := []*Encrypter{
+		newGCMEncrypter(block, nonce, nil),
+		newGCMEncrypter(block, nonce, nil),
+	}
+	got := make([]byte, n)
+
+	// AllocsPerRun makes one warm-up call before the measured one.
+	allocs := testing.AllocsPerRun(1, func() {
+		gcm := encrypters[0]
+		encrypters = encrypters[1:]
+
+		for i := range plaintext {
+			gcm.Encrypt(got[i:i], plaintext[i:i+1])
+			if gcm.ghashTailNb >= gcmBlockSize {
+				panic("GHASH tail holds a full block")
+			}
+		}
+	})
+	assert.Zero(t, allocs)
+
+	assert.Equal(t, want, got)
+}
+
+func TestEncryptSplitTag(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	whole := newGCMEncrypter(block, nonce, nil)
+	whole.Encrypt(nil, decryptedPacket)
+
+	split := newGCMEncrypter(block, nonce, nil)
+	for i := range decryptedPacket {
+		split.Encrypt(nil, decryptedPacket[i:i+1])
+	}
+
+	assert.Equal(t, whole.Tag(), split.Tag())
+}
+
+func TestDecryptSingleBytesBounded(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	decrypters := []*Decrypter{
+		newGCMDecrypter(block, nonce, nil),
+		newGCMDecrypter(block, nonce, nil),
+	}
+	got := make([]byte, len(encryptedPacket))
+
+	allocs := testing.AllocsPerRun(1, func() {
+		gcm := decrypters[0]
+		decrypters = decrypters[1:]
+
+		for i := range encryptedPacket {
+			gcm.Decrypt(got[i:i], encryptedPacket[i:i+1])
+		}
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, decryptedPacket, got)
+}
+
+func TestCounters(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	gcm := newGCMEncrypter(block, nonce, nil)
+
+	initial := gcm.InitialCounter()
+	assert.Equal(t, initial, gcm.FinalCounter())
+
+	j0 := initial
+	binary.BigEndian.PutUint32(j0[12:], binary.BigEndian.Uint32(j0[12:])-1)
+
+	var tagMask [gcmBlockSize]byte
+	block.Encrypt(tagMask[:], j0[:])
+	assert.Equal(t, tagMask, gcm.tagMask)
+
+	gcm.Encrypt(nil, decryptedPacket[:4])
+	gcm.Encrypt(nil, decryptedPacket[4:])
+
+	final := gcm.FinalCounter()
+	assert.Equal(t, initial[:12], final[:12])
+	assert.Equal(t, uint32(2), binary.BigEndian.Uint32(final[12:])-binary.BigEndian.Uint32(initial[12:]))
+	assert.Equal(t, initial, gcm.InitialCounter())
+}
+
+// countingBlock counts the blocks passed to Encrypt.
+type countingBlock struct {
+	cipher.Block
+	calls int
+}
+
+func (b *countingBlock) Encrypt(dst, src []byte) {
+	b.calls++
+	b.Block.Encrypt(dst, src)
+}
+
+// strictBlock panics if Encrypt is given overlapping src and dst.
+type strictBlock struct {
+	cipher.Block
+}
+
+func (b strictBlock) Encrypt(dst, src []byte) {
+	if &dst[0] == &src[0] || inexactOverlap(dst[:gcmBlockSize], src[:gcmBlockSize]) {
+		panic("overlapping Encrypt arguments")
+	}
+	b.Block.Encrypt(dst, src)
+}
+
+func TestBlockArgumentsDoNotOverlap(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	block := strictBlock{aesBlock}
+
+	for _, n := range [][]byte{nonce, nonce[:gcmStandardNonceSize]} {
+		assert.NotPanics(t, func() {
+			e := newGCMEncrypter(block, n, []byte("ad"))
+			e.Reserve(40)
+			ciphertext := e.Encrypt(nil, make([]byte, 33))
+			ciphertext = e.Encrypt(ciphertext, make([]byte, 50))
+			tag := e.Tag()
+
+			d := newGCMDecrypter(block, n, []byte("ad"))
+			_, err := d.Decrypt(nil, ciphertext)
+			assert.Nil(t, err)
+			assert.Nil(t, d.VerifyArray(tag))
+		})
+	}
+}
+
+func TestReserve(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 100)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	want := newGCMEncrypter(aesBlock, nonce, nil)
+	wantCiphertext := want.Encrypt(nil, plaintext)
+
+	block := &countingBlock{Block: aesBlock}
+	gcm := newGCMEncrypter(block, nonce, nil)
+
+	ciphertext := gcm.Encrypt(nil, plaintext[:5])
+	gcm.Reserve(len(plaintext) - 5)
+
+	calls := block.calls
+	for i := 5; i < len(plaintext); i += 7 {
+		ciphertext = gcm.Encrypt(ciphertext, plaintext[i:min(i+7, len(plaintext))])
+	}
+	assert.Equal(t, calls, block.calls)
+
+	assert.Equal(t, wantCiphertext, ciphertext)
+	assert.Equal(t, want.Tag(), gcm.Tag())
+}
+
+func TestGMAC(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCMWithNonceSize(aesBlock, gcmNonceSize)
+	assert.Nil(t, err)
+
+	additionalData := []byte("authenticated but not encrypted")
+
+	block := &countingBlock{Block: aesBlock}
+	gcm := newGCMEncrypter(block, nonce, additionalData)
+	gcm.Encrypt(nil, nil)
+	tag := gcm.Tag()
+
+	// One block for the hash subkey and one for the tag mask; no keystream.
+	assert.Equal(t, 2, block.calls)
+	assert.Equal(t, aead.Seal(nil, nonce, nil, additionalData), tag[:])
+}
+
+// randomChunks splits n bytes into random chunk sizes, biased towards
+// sizes around the block size and including empty chunks.
+func randomChunks(rng *rand.Rand, n int) []int {
+	var chunks []int
+	for n > 0 {
+		var c int
+		switch rng.Intn(4) {
+		case 0:
+			c = rng.Intn(2 * gcmBlockSize)
+		case 1:
+			c = gcmBlockSize*rng.Intn(4) + rng.Intn(3) - 1
+		default:
+			c = rng.Intn(n + 1)
+		}
+		c = max(0, min(c, n))
+		chunks = append(chunks, c)
+		n -= c
+	}
+	return chunks
+}
+
+// TestChunkingIndependence checks the property the keystream and GHASH tail
+// buffering exist for: however the message is split across Encrypt and
+// Decrypt calls, the ciphertext, plaintext and tag are those of a single
+// call.
+func TestChunkingIndependence(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	configs := map[string]func(g *gcm){
+		"default":  func(g *gcm) {},
+		"batch1":   func(g *gcm) { g.SetCounterBatch(1) },
+		"batch3":   func(g *gcm) { g.SetCounterBatch(3) },
+		"ctpad":    func(g *gcm) { g.SetConstantTimePadding(true) },
+		"lazy":     func(g *gcm) { g.deferAD = true },
+		"mte":      func(g *gcm) { g.macPlaintext = true },
+		"reserved": func(g *gcm) {},
+	}
+
+	rng := rand.New(rand.NewSource(1))
+	ad := []byte("additional data!!")
+
+	for name, configure := range configs {
+		for _, block := range []cipher.Block{aesBlock, &bulkBlock{Block: aesBlock}} {
+			newPair := func() (*Encrypter, *Decrypter) {
+				e, d := newGCM(block), newGCM(block)
+				configure(e)
+				configure(d)
+				return e.newEncrypter(nonce, ad), d.newDecrypter(nonce, ad)
+			}
+
+			for i := 0; i < 30; i++ {
+				size := rng.Intn(10 * gcmBlockSize)
+				if i == 0 {
+					size = pipelineThreshold + 37
+				}
+				plaintext := make([]byte, size)
+				rng.Read(plaintext)
+
+				whole, _ := newPair()
+				want := whole.Encrypt(nil, plaintext)
+				wantTag := whole.Tag()
+
+				e, d := newPair()
+				if name == "reserved" {
+					e.Reserve(rng.Intn(3 * gcmBlockSize))
+				}
+
+				var ciphertext []byte
+				off := 0
+				for _, c := range randomChunks(rng, size) {
+					ciphertext = e.Encrypt(ciphertext, plaintext[off:off+c])
+					off += c
+				}
+				assert.Equal(t, want, ciphertext, "%s: size %d", name, size)
+				assert.Equal(t, wantTag, e.Tag(), "%s: size %d", name, size)
+
+				decrypted := []byte{}
+				off = 0
+				for _, c := range randomChunks(rng, size) {
+					decrypted, err = d.Decrypt(decrypted, ciphertext[off:off+c])
+					assert.Nil(t, err)
+					off += c
+				}
+				assert.Equal(t, plaintext, decrypted, "%s: size %d", name, size)
+				assert.Nil(t, d.VerifyArray(wantTag), "%s: size %d", name, size)
+			}
+		}
+	}
+}
+
+// TestDecryptWorkIsDataIndependent checks the timing guarantee documented on
+// Decrypt: the block cipher calls and GHASH multiplications depend only on
+// the ciphertext length, not its contents, and Verify does the same work for
+// a matching and a mismatching tag.
+func TestDecryptWorkIsDataIndependent(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+
+	for _, size := range []int{0, 1, 15, 16, 17, 100, 4 * gcmBlockSize} {
+		plaintext := make([]byte, size)
+		rng.Read(plaintext)
+		e := newGCMEncrypter(aesBlock, nonce, nil)
+		valid := e.Encrypt(nil, plaintext)
+		validTag := e.Tag()
+		random := make([]byte, size)
+		rng.Read(random)
+		ones := bytes.Repeat([]byte{0xff}, size)
+
+		var calls []int
+		var blocks []uint64
+		verified := 0
+		for _, ciphertext := range [][]byte{valid, random, ones, make([]byte, size)} {
+			for _, tag := range [][]byte{validTag[:], make([]byte, gcmTagSize)} {
+				block := &countingBlock{Block: aesBlock}
+				d := newGCMDecrypter(block, nonce, nil)
+				before := block.calls
+
+				_, err := d.Decrypt(nil, ciphertext)
+				assert.Nil(t, err)
+				if d.Verify(tag) == nil {
+					verified++
+				}
+
+				calls = append(calls, block.calls-before)
+				blocks = append(blocks, d.ghashBlocks)
+			}
+		}
+
+		// Only the valid ciphertext with its own tag verifies, yet every
+		// case did the same work. At size 0 all four ciphertexts are the
+		// same empty message.
+		wantVerified := 1
+		if size == 0 {
+			wantVerified = 4
+		}
+		assert.Equal(t, wantVerified, verified, "size %d", size)
+		for i := 1; i < len(calls); i++ {
+			assert.Equal(t, calls[0], calls[i], "size %d", size)
+			assert.Equal(t, blocks[0], blocks[i], "size %d", size)
+		}
+		assert.Equal(t, (size+gcmBlockSize-1)/gcmBlockSize, calls[0], "size %d", size)
+		assert.Equal(t, uint64((size+gcmBlockSize-1)/gcmBlockSize), blocks[0], "size %d", size)
+	}
+}
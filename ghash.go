@@ -0,0 +1,8 @@
+package uncheckedgcm
+
+// Implementation names the GHASH implementation in use. There is no
+// assembly implementation in this package, so it is always "generic", the
+// portable 4-bit table multiply.
+func (g *gcm) Implementation() string {
+	return "generic"
+}
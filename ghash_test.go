@@ -0,0 +1,72 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// referenceGHASH is a bit-at-a-time GHASH used to check the table-driven
+// multiply. It shares no code with it.
+type referenceGHASH struct {
+	h, y gcmFieldElement
+}
+
+func (r *referenceGHASH) Update(data []byte) {
+	for len(data) > 0 {
+		var block [gcmBlockSize]byte
+		n := copy(block[:], data)
+		data = data[n:]
+
+		r.y.low ^= binary.BigEndian.Uint64(block[:8])
+		r.y.high ^= binary.BigEndian.Uint64(block[8:])
+		r.y = gcmMul(&r.y, &r.h)
+	}
+}
+
+func (r *referenceGHASH) Sum() [gcmBlockSize]byte {
+	var sum [gcmBlockSize]byte
+	binary.BigEndian.PutUint64(sum[:8], r.y.low)
+	binary.BigEndian.PutUint64(sum[8:], r.y.high)
+	return sum
+}
+
+// TestTableGHASH checks update against referenceGHASH for a range of
+// subkeys, input lengths and splits.
+func TestTableGHASH(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		var h [gcmBlockSize]byte
+		rng.Read(h[:])
+
+		ref := &referenceGHASH{h: gcmFieldElement{
+			binary.BigEndian.Uint64(h[:8]),
+			binary.BigEndian.Uint64(h[8:]),
+		}}
+		g := &gcm{}
+		g.setSubkey(h)
+		var y gcmFieldElement
+
+		for j := 0; j < 5; j++ {
+			data := make([]byte, rng.Intn(5*gcmBlockSize))
+			rng.Read(data)
+
+			ref.Update(data)
+			g.update(&y, data)
+
+			assert.Equal(t, ref.y, y, "subkey %x, update %d", h, j)
+		}
+	}
+}
+
+func TestImplementation(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	assert.Equal(t, "generic", newGCMEncrypter(block, nonce, nil).Implementation())
+	assert.Equal(t, "generic", newGCMDecrypter(block, nonce, nil).Implementation())
+}
@@ -0,0 +1,76 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// adAccumulator is a second running GHASH that holds the additional data of
+// an interleaved message apart from its ciphertext. Like updateStream it
+// buffers an unaligned tail, so segment boundaries do not affect the tag.
+type adAccumulator struct {
+	y      gcmFieldElement
+	tail   [gcmBlockSize]byte
+	tailNb int
+}
+
+func (a *adAccumulator) update(g *gcm, data []byte) {
+	if a.tailNb > 0 {
+		n := copy(a.tail[a.tailNb:], data)
+		a.tailNb += n
+		data = data[n:]
+
+		if a.tailNb < gcmBlockSize {
+			return
+		}
+		g.updateBlocks(&a.y, a.tail[:])
+		a.tailNb = 0
+	}
+
+	fullBlocks := (len(data) >> 4) << 4
+	g.updateBlocks(&a.y, data[:fullBlocks])
+	a.tailNb = copy(a.tail[:], data[fullBlocks:])
+}
+
+// sum returns the GHASH of all additional data, zero-padded to a whole
+// number of blocks, without modifying a.
+func (a *adAccumulator) sum(g *gcm) gcmFieldElement {
+	y := a.y
+	if a.tailNb > 0 {
+		g.update(&y, a.tail[:a.tailNb])
+	}
+	return y
+}
+
+// newInterleavedGCMEncrypter returns an encrypter for protocols that mix
+// authenticated-only segments with encrypted ones under a single tag.
+// AddAdditionalData may be called at any point before Tag, between Encrypt
+// calls as well as before them. The tag is that of standard GCM with all
+// the additional data, in the order given, hashed before all the ciphertext.
+//
+// The additional data goes into a second GHASH accumulator rather than being
+// buffered. At the tag, that accumulator is multiplied by H raised to the
+// number of ciphertext blocks, which moves it in front of the ciphertext.
+func newInterleavedGCMEncrypter(cipher cipher.Block, nonce, additionalData []byte) *Encrypter {
+	g := newGCM(cipher)
+	g.interleaved = true
+	return g.newEncrypter(nonce, additionalData)
+}
+
+// newInterleavedGCMDecrypter is the decrypting counterpart of
+// newInterleavedGCMEncrypter.
+func newInterleavedGCMDecrypter(cipher cipher.Block, nonce, additionalData []byte) *Decrypter {
+	g := newGCM(cipher)
+	g.interleaved = true
+	return g.newDecrypter(nonce, additionalData)
+}
+
+// foldInterleavedAD adds the separately accumulated additional data to y, a
+// GHASH state over blocks of data.
+func (g *gcm) foldInterleavedAD(y *gcmFieldElement, blocks uint64) {
+	if !g.interleaved {
+		return
+	}
+
+	a := g.interleavedAD.sum(g)
+	power := g.field.pow(g.productTable[reverseBits(1)], blocks)
+	a = g.field.mul(&a, &power)
+	*y = gcmAdd(y, &a)
+}
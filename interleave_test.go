@@ -0,0 +1,67 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterleavedMatchesReordered(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+
+	for i := 0; i < 50; i++ {
+		e := newInterleavedGCMEncrypter(block, nonce, []byte("header"))
+		d := newInterleavedGCMDecrypter(block, nonce, []byte("header"))
+
+		ad := []byte("header")
+		var plaintext, ciphertext, decrypted []byte
+
+		for j := rng.Intn(8); j >= 0; j-- {
+			segment := make([]byte, rng.Intn(40))
+			rng.Read(segment)
+
+			if rng.Intn(2) == 0 {
+				e.AddAdditionalData(segment)
+				d.AddAdditionalData(segment)
+				ad = append(ad, segment...)
+				continue
+			}
+
+			n := len(ciphertext)
+			ciphertext = e.Encrypt(ciphertext, segment)
+			decrypted, err = d.Decrypt(decrypted, ciphertext[n:])
+			assert.Nil(t, err)
+			plaintext = append(plaintext, segment...)
+		}
+
+		// The reference hashes all the additional data first.
+		reference := newGCMEncrypter(block, nonce, ad)
+		assert.Equal(t, reference.Encrypt(nil, plaintext), ciphertext)
+		assert.Equal(t, reference.PeekTag(), e.PeekTag())
+
+		tag := e.Tag()
+		assert.Equal(t, reference.Tag(), tag)
+		assert.Equal(t, plaintext, decrypted)
+		assert.Nil(t, d.VerifyArray(tag))
+	}
+}
+
+func TestInterleavedADAfterTag(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newInterleavedGCMEncrypter(block, nonce, nil)
+	e.Encrypt(nil, decryptedPacket)
+	e.Tag()
+	assert.PanicsWithValue(t, ErrFinalized, func() { e.AddAdditionalData([]byte("late")) })
+
+	// Standard encrypters still refuse additional data after data.
+	s := newGCMEncrypter(block, nonce, nil)
+	s.Encrypt(nil, decryptedPacket)
+	assert.Panics(t, func() { s.AddAdditionalData([]byte("late")) })
+}
@@ -0,0 +1,132 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptMatchesStdlib(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+
+	for n := 0; n <= 6*gcmBlockSize; n++ {
+		plaintext := make([]byte, n)
+		for i := range plaintext {
+			plaintext[i] = byte(i * 7)
+		}
+
+		gcm := newGCMEncrypter(block, nonce, nil)
+		ciphertext := gcm.Encrypt(nil, plaintext)
+		tag := gcm.Tag()
+
+		assert.Equal(t, aead.Seal(nil, nonce, plaintext, nil), append(ciphertext, tag[:]...), "length %d", n)
+	}
+}
+
+func TestInteropWithStdlib(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	assert.Nil(t, err)
+
+	additionalData := []byte("twenty bytes of data")
+	plaintext := make([]byte, 1000)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	for _, chunk := range []int{1, 3, 16, 17, 100, len(plaintext)} {
+		// Chunked encryption opens with crypto/cipher.
+		enc := newGCMEncrypter(block, nonce, additionalData)
+		var sealed []byte
+		for i := 0; i < len(plaintext); i += chunk {
+			sealed = enc.Encrypt(sealed, plaintext[i:min(i+chunk, len(plaintext))])
+		}
+		tag := enc.Tag()
+		sealed = append(sealed, tag[:]...)
+
+		opened, err := aead.Open(nil, nonce, sealed, additionalData)
+		assert.Nil(t, err, "chunk %d", chunk)
+		assert.Equal(t, plaintext, opened)
+
+		// crypto/cipher output decrypts and verifies chunk by chunk.
+		sealed = aead.Seal(nil, nonce, plaintext, additionalData)
+		ciphertext, tag2 := sealed[:len(plaintext)], sealed[len(plaintext):]
+
+		dec := newGCMDecrypter(block, nonce, additionalData)
+		var decrypted []byte
+		for i := 0; i < len(ciphertext); i += chunk {
+			decrypted, err = dec.Decrypt(decrypted, ciphertext[i:min(i+chunk, len(ciphertext))])
+			assert.Nil(t, err)
+		}
+		assert.Equal(t, plaintext, decrypted)
+		assert.Nil(t, dec.Verify(tag2), "chunk %d", chunk)
+	}
+}
+
+// benchmarkSizes are the message sizes for comparing against crypto/cipher,
+// which uses hardware AES and GHASH where available.
+var benchmarkSizes = []int{64, 1024, 16 * 1024, 1024 * 1024}
+
+// BenchmarkStreamingEncrypt encrypts each message in 1 KiB chunks and then
+// computes the tag, as a streaming caller would.
+func BenchmarkStreamingEncrypt(b *testing.B) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			plaintext := make([]byte, size)
+			buf := make([]byte, 0, 1024)
+
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				g := newGCMEncrypter(block, nonce, nil)
+				for chunk := plaintext; len(chunk) > 0; {
+					n := min(len(chunk), 1024)
+					buf = g.Encrypt(buf[:0], chunk[:n])
+					chunk = chunk[n:]
+				}
+				g.Tag()
+			}
+		})
+	}
+}
+
+// BenchmarkStdlibSeal seals each message in one call with crypto/cipher.
+func BenchmarkStdlibSeal(b *testing.B) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+	aead, err := cipher.NewGCMWithNonceSize(block, gcmNonceSize)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, size := range benchmarkSizes {
+		b.Run(fmt.Sprint(size), func(b *testing.B) {
+			plaintext := make([]byte, size)
+			buf := make([]byte, 0, size+gcmTagSize)
+
+			b.SetBytes(int64(size))
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				buf = aead.Seal(buf[:0], nonce, plaintext, nil)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"fmt"
+)
+
+// newGCMWithSubkey is like newGCM but uses h as the hash subkey instead of
+// the encryption of the zero block, for protocols that derive H separately.
+// The result is not GCM unless h equals that encryption.
+func newGCMWithSubkey(cipher cipher.Block, h [gcmBlockSize]byte) *gcm {
+	g := &gcm{
+		cipher: cipher,
+		field:  standardField,
+	}
+	g.setSubkey(h)
+
+	return g
+}
+
+// hkdfSHA256 returns n bytes of HKDF-SHA256 output (RFC 5869) for secret,
+// salt and info. n must be at most 255 hash lengths.
+func hkdfSHA256(secret, salt, info []byte, n int) []byte {
+	if n > 255*sha256.Size {
+		panic("gcm: HKDF output too long")
+	}
+
+	if salt == nil {
+		salt = make([]byte, sha256.Size)
+	}
+	extract := hmac.New(sha256.New, salt)
+	extract.Write(secret)
+	prk := extract.Sum(nil)
+
+	expand := hmac.New(sha256.New, prk)
+	out := make([]byte, 0, n+sha256.Size)
+	var t []byte
+	for i := byte(1); len(out) < n; i++ {
+		expand.Reset()
+		expand.Write(t)
+		expand.Write(info)
+		expand.Write([]byte{i})
+		t = expand.Sum(t[:0])
+		out = append(out, t...)
+	}
+	clear(prk)
+	clear(t)
+
+	return out[:n]
+}
+
+// deriveKeyAndSubkey expands secret with HKDF-SHA256 into a keySize-byte
+// AES key followed by a 16-byte hash subkey.
+func deriveKeyAndSubkey(secret, salt, info []byte, keySize int) (key []byte, h [gcmBlockSize]byte) {
+	okm := hkdfSHA256(secret, salt, info, keySize+gcmBlockSize)
+	key = okm[:keySize:keySize]
+	copy(h[:], okm[keySize:])
+	clear(okm[keySize:])
+	return key, h
+}
+
+// newGCMFromSecret derives an AES key of keySize bytes and the hash subkey
+// from secret with deriveKeyAndSubkey and returns a gcm using both. Start
+// it with newEncrypter or newDecrypter.
+func newGCMFromSecret(secret, salt, info []byte, keySize int) (*gcm, error) {
+	key, h := deriveKeyAndSubkey(secret, salt, info, keySize)
+	defer clear(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: creating AES cipher: %w", err)
+	}
+
+	return newGCMWithSubkey(block, h), nil
+}
@@ -0,0 +1,66 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHKDFSHA256(t *testing.T) {
+	// RFC 5869, test case 1.
+	ikm := decodeHex(t, "0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b0b")
+	salt := decodeHex(t, "000102030405060708090a0b0c")
+	info := decodeHex(t, "f0f1f2f3f4f5f6f7f8f9")
+	want := decodeHex(t, "3cb25f25faacd57a90434f64d0362f2a2d2d0a90cf1a5a4c5db02d56ecc4c5bf"+
+		"34007208d5b887185865")
+
+	assert.Equal(t, want, hkdfSHA256(ikm, salt, info, len(want)))
+	assert.Equal(t, want[:10], hkdfSHA256(ikm, salt, info, 10))
+}
+
+func TestSubkeyFromSecret(t *testing.T) {
+	secret := []byte("master secret")
+	key, h := deriveKeyAndSubkey(secret, nil, []byte("info"), 16)
+
+	okm := hkdfSHA256(secret, nil, []byte("info"), 32)
+	assert.Equal(t, okm[:16], key)
+	assert.Equal(t, okm[16:], h[:])
+
+	derived, err := newGCMFromSecret(secret, nil, []byte("info"), 16)
+	assert.Nil(t, err)
+
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	injected := newGCMWithSubkey(block, h)
+
+	assert.Equal(t, injected.productTable, derived.productTable)
+	assert.Equal(t, gcmFieldElement{
+		binary.BigEndian.Uint64(h[:8]),
+		binary.BigEndian.Uint64(h[8:]),
+	}, derived.productTable[reverseBits(1)])
+
+	// Injecting E(0) gives standard GCM.
+	var zero, e0 [gcmBlockSize]byte
+	block.Encrypt(e0[:], zero[:])
+	assert.Equal(t, newGCM(block).productTable, newGCMWithSubkey(block, e0).productTable)
+
+	// A message round-trips under the derived subkey, and the tag differs
+	// from standard GCM under the same key.
+	e := derived.fork().newEncrypter(nonce[:gcmStandardNonceSize], nil)
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	d := injected.fork().newDecrypter(nonce[:gcmStandardNonceSize], nil)
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, d.VerifyArray(tag))
+
+	standard := newGCMEncrypter(block, nonce[:gcmStandardNonceSize], nil)
+	standard.Encrypt(nil, decryptedPacket)
+	assert.NotEqual(t, standard.Tag(), tag)
+
+	_, err = newGCMFromSecret(secret, nil, nil, 10)
+	assert.NotNil(t, err)
+}
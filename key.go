@@ -0,0 +1,69 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+)
+
+// NewFromKey returns an AES-GCM encrypter for key, which must be 16, 24 or
+// 32 bytes long. Unlike newGCMEncrypter, an invalid key or nonce is reported
+// as an error rather than a panic.
+func NewFromKey(key, nonce, additionalData []byte) (*Encrypter, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: creating AES cipher: %w", err)
+	}
+
+	if err := Validate(nonce, additionalData); err != nil {
+		return nil, err
+	}
+
+	return newGCMEncrypter(block, nonce, additionalData), nil
+}
+
+// NewDecrypterFromKey is the decrypting counterpart of NewFromKey.
+func NewDecrypterFromKey(key, nonce, additionalData []byte) (*Decrypter, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: creating AES cipher: %w", err)
+	}
+
+	if err := Validate(nonce, additionalData); err != nil {
+		return nil, err
+	}
+
+	return newGCMDecrypter(block, nonce, additionalData), nil
+}
+
+// Key holds an AES key schedule and its GHASH product table so that many
+// messages under the same key can be started without repeating either.
+// It is safe for concurrent use.
+type Key struct {
+	base *gcm
+}
+
+// NewKey expands key, which must be 16, 24 or 32 bytes long.
+func NewKey(key []byte) (*Key, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("gcm: creating AES cipher: %w", err)
+	}
+	return newKeyFromBlock(block), nil
+}
+
+func newKeyFromBlock(cipher cipher.Block) *Key {
+	return &Key{base: newGCM(cipher)}
+}
+
+// NewEncrypter returns an encrypter for one message. It panics if the nonce
+// is invalid, like newGCMEncrypter.
+func (k *Key) NewEncrypter(nonce, additionalData []byte) *Encrypter {
+	return k.base.fork().newEncrypter(nonce, additionalData)
+}
+
+// NewDecrypter returns a decrypter for one message. It panics if the nonce
+// is invalid, like newGCMDecrypter.
+func (k *Key) NewDecrypter(nonce, additionalData []byte) *Decrypter {
+	return k.base.fork().newDecrypter(nonce, additionalData)
+}
@@ -0,0 +1,107 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFromKey(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	want := newGCMEncrypter(block, nonce, []byte("ad"))
+	wantCiphertext := want.Encrypt(nil, decryptedPacket)
+
+	e, err := NewFromKey(key, nonce, []byte("ad"))
+	assert.Nil(t, err)
+	assert.Equal(t, wantCiphertext, e.Encrypt(nil, decryptedPacket))
+	tag := e.Tag()
+	assert.Equal(t, want.Tag(), tag)
+
+	d, err := NewDecrypterFromKey(key, nonce, []byte("ad"))
+	assert.Nil(t, err)
+	plaintext, err := d.OpenDetached(wantCiphertext, tag[:])
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+}
+
+func TestNewFromKeyInvalidKey(t *testing.T) {
+	for _, n := range []int{0, 8, 15, 17, 31, 33, 64} {
+		_, err := NewFromKey(make([]byte, n), nonce, nil)
+		var sizeErr aes.KeySizeError
+		assert.ErrorAs(t, err, &sizeErr)
+		assert.Equal(t, aes.KeySizeError(n), sizeErr)
+
+		_, err = NewDecrypterFromKey(make([]byte, n), nonce, nil)
+		assert.ErrorAs(t, err, &sizeErr)
+	}
+
+	for _, n := range []int{16, 24, 32} {
+		_, err := NewFromKey(make([]byte, n), nonce, nil)
+		assert.Nil(t, err)
+	}
+}
+
+func TestNewFromKeyInvalidNonce(t *testing.T) {
+	_, err := NewFromKey(key, nil, nil)
+	assert.ErrorIs(t, err, errNonceSize)
+
+	_, err = NewDecrypterFromKey(key, nil, nil)
+	assert.ErrorIs(t, err, errNonceSize)
+}
+
+func TestKey(t *testing.T) {
+	k, err := NewKey(key)
+	assert.Nil(t, err)
+
+	_, err = NewKey(key[:15])
+	var sizeErr aes.KeySizeError
+	assert.ErrorAs(t, err, &sizeErr)
+
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, n := range [][]byte{nonce, nonce[:gcmStandardNonceSize]} {
+		want := newGCMEncrypter(block, n, []byte("ad"))
+		wantCiphertext := want.Encrypt(nil, decryptedPacket)
+		wantTag := want.Tag()
+
+		// Messages from the same Key do not share state.
+		e1 := k.NewEncrypter(n, []byte("ad"))
+		e2 := k.NewEncrypter(n, []byte("ad"))
+		assert.Equal(t, wantCiphertext, e1.Encrypt(nil, decryptedPacket))
+		assert.Equal(t, wantCiphertext, e2.Encrypt(nil, decryptedPacket))
+		assert.Equal(t, wantTag, e1.Tag())
+		assert.Equal(t, wantTag, e2.Tag())
+
+		d := k.NewDecrypter(n, []byte("ad"))
+		plaintext, err := d.OpenDetached(wantCiphertext, wantTag[:])
+		assert.Nil(t, err)
+		assert.Equal(t, decryptedPacket, plaintext)
+	}
+}
+
+func BenchmarkNewEncrypterFromKey(b *testing.B) {
+	k, err := NewKey(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		k.NewEncrypter(nonce, nil)
+	}
+}
+
+func BenchmarkNewEncrypterFromScratch(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			b.Fatal(err)
+		}
+		newGCMEncrypter(block, nonce, nil)
+	}
+}
@@ -0,0 +1,106 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// newLazyGCMEncrypter is like newGCMEncrypter but defers hashing
+// additionalData until the tag is first requested, which saves the work
+// entirely for messages whose tag is never computed. additionalData is
+// copied, so the caller may reuse it immediately.
+func newLazyGCMEncrypter(cipher cipher.Block, nonce, additionalData []byte) *Encrypter {
+	g := newGCM(cipher)
+	g.deferAD = true
+	return g.newEncrypter(nonce, additionalData)
+}
+
+// newLazyGCMDecrypter is like newGCMDecrypter but defers hashing
+// additionalData until the tag is first requested. additionalData is copied,
+// so the caller may reuse it immediately.
+func newLazyGCMDecrypter(cipher cipher.Block, nonce, additionalData []byte) *Decrypter {
+	g := newGCM(cipher)
+	g.deferAD = true
+	return g.newDecrypter(nonce, additionalData)
+}
+
+// gcmPow returns x^n in GCM's field.
+func gcmPow(x gcmFieldElement, n uint64) gcmFieldElement {
+	return standardField.pow(x, n)
+}
+
+// foldAD adds additional data to y as if it had been hashed before the
+// blocks already in y. GHASH is linear, so hashing A then C from zero equals
+// GHASH(A)·H^c + GHASH(C), where c is the number of blocks in C.
+func (g *gcm) foldAD(y *gcmFieldElement, additionalData []byte, blocks uint64) {
+	if len(additionalData) == 0 {
+		return
+	}
+
+	var a gcmFieldElement
+	g.update(&a, additionalData)
+
+	power := g.field.pow(g.productTable[reverseBits(1)], blocks)
+	a = g.field.mul(&a, &power)
+	*y = gcmAdd(y, &a)
+}
+
+// foldLazyAD hashes any deferred additional data into the running GHASH.
+// It must be called after flush. Afterwards lazyAD is nil, which marks the
+// additional data as final.
+func (g *gcm) foldLazyAD() {
+	g.foldAD(&g.ghash, g.lazyAD, g.ghashBlocks)
+	g.lazyAD = nil
+	g.foldInterleavedAD(&g.ghash, g.ghashBlocks)
+}
+
+// addTrailingAD appends trailer to the deferred additional data.
+func (g *gcm) addTrailingAD(trailer []byte) {
+	if !g.deferAD {
+		panic("gcm: trailing additional data requires a lazy constructor")
+	}
+	if g.lazyAD == nil {
+		panic("gcm: trailing additional data added after the tag")
+	}
+	g.lazyAD = append(g.lazyAD, trailer...)
+}
+
+// AddTrailingAD authenticates trailer as additional data that follows the
+// plaintext on the wire. GCM hashes all additional data before the
+// ciphertext, so the tag is that of standard GCM with additional data
+// header||trailer, where header is the additional data given to the
+// constructor. It may be called several times, each call appending to the
+// trailer, but only on an encrypter from newLazyGCMEncrypter and only before
+// Tag.
+func (g *Encrypter) AddTrailingAD(trailer []byte) {
+	g.addTrailingAD(trailer)
+	g.additionalDataNb += uint64(len(trailer))
+}
+
+// AddTrailingAD authenticates trailer as additional data that follows the
+// ciphertext on the wire, as for the encrypter. It may only be used on a
+// decrypter from newLazyGCMDecrypter and only before Tag or Verify.
+func (g *Decrypter) AddTrailingAD(trailer []byte) {
+	g.addTrailingAD(trailer)
+	g.additionalDataNb += uint64(len(trailer))
+}
+
+// CombineGHASH returns the GHASH of several messages hashed one after
+// another, given the GHASH of each message on its own from a zero state and
+// its length in bytes. Each message is zero-padded to a whole number of
+// blocks, as update does, so the result equals calling update on each
+// message in turn. GHASH is linear, so every earlier state is multiplied by
+// H raised to the number of blocks that follow it.
+func (g *gcm) CombineGHASH(states []gcmFieldElement, lengths []int) gcmFieldElement {
+	if len(states) != len(lengths) {
+		panic("gcm: mismatched GHASH states and lengths")
+	}
+
+	h := g.productTable[reverseBits(1)]
+
+	var acc gcmFieldElement
+	for i, state := range states {
+		blocks := (uint64(lengths[i]) + gcmBlockSize - 1) / gcmBlockSize
+		power := g.field.pow(h, blocks)
+		acc = g.field.mul(&acc, &power)
+		acc = gcmAdd(&acc, &state)
+	}
+	return acc
+}
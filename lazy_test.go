@@ -0,0 +1,142 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLazyAD(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, adLen := range []int{0, 1, 16, 17, 40} {
+		for _, ptLen := range []int{0, 1, 15, 16, 33, 64} {
+			ad := make([]byte, adLen)
+			for i := range ad {
+				ad[i] = byte(i + 7)
+			}
+			plaintext := make([]byte, ptLen)
+			for i := range plaintext {
+				plaintext[i] = byte(i)
+			}
+
+			want := newGCMEncrypter(block, nonce, ad)
+			wantCiphertext := want.Encrypt(nil, plaintext)
+			wantPeek := want.PeekTag()
+			wantTag := want.Tag()
+
+			lazy := newLazyGCMEncrypter(block, nonce, ad)
+			clear(ad) // the constructor must have taken a copy
+			ciphertext := lazy.Encrypt(nil, plaintext[:ptLen/2])
+			ciphertext = lazy.Encrypt(ciphertext, plaintext[ptLen/2:])
+			assert.Equal(t, wantCiphertext, ciphertext)
+			assert.Equal(t, wantPeek, lazy.PeekTag())
+			assert.Equal(t, wantTag, lazy.Tag())
+
+			ad = make([]byte, adLen)
+			for i := range ad {
+				ad[i] = byte(i + 7)
+			}
+			d := newLazyGCMDecrypter(block, nonce, ad)
+			_, err := d.Decrypt(nil, ciphertext)
+			assert.Nil(t, err)
+			assert.Nil(t, d.VerifyArray(wantTag))
+		}
+	}
+}
+
+func TestLazyADSkipsHashing(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	g := newLazyGCMEncrypter(block, nonce, make([]byte, 64))
+	assert.Zero(t, g.ghashBlocks)
+	assert.Equal(t, gcmFieldElement{}, g.ghash)
+}
+
+func TestGCMPow(t *testing.T) {
+	x := gcmFieldElement{0x66e94bd4ef8a2c3b, 0x884cfa59ca342b2e}
+
+	want := gcmOne
+	for n := uint64(0); n < 20; n++ {
+		assert.Equal(t, want, gcmPow(x, n))
+		want = gcmMul(&want, &x)
+	}
+}
+
+func TestTrailingAD(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	header := []byte("header: 21 bytes long")
+	trailer := []byte("trailer")
+	plaintext := []byte("payload between header and trailer")
+
+	e := newLazyGCMEncrypter(block, nonce[:gcmStandardNonceSize], header)
+	ciphertext := e.Encrypt(nil, plaintext)
+	e.AddTrailingAD(trailer[:3])
+	e.AddTrailingAD(trailer[3:])
+	tag := e.Tag()
+
+	aead, err := cipher.NewGCM(block)
+	assert.Nil(t, err)
+	ad := append(append([]byte(nil), header...), trailer...)
+	want := aead.Seal(nil, nonce[:gcmStandardNonceSize], plaintext, ad)
+	assert.Equal(t, want, append(ciphertext, tag[:]...))
+
+	open := func(header, trailer []byte) error {
+		d := newLazyGCMDecrypter(block, nonce[:gcmStandardNonceSize], header)
+		_, err := d.Decrypt(nil, ciphertext)
+		assert.Nil(t, err)
+		d.AddTrailingAD(trailer)
+		return d.VerifyArray(tag)
+	}
+
+	assert.Nil(t, open(header, trailer))
+	assert.ErrorIs(t, open([]byte("Header: 21 bytes long"), trailer), ErrOpen)
+	assert.ErrorIs(t, open(header, []byte("trailes")), ErrOpen)
+	assert.ErrorIs(t, open(header, trailer[:6]), ErrOpen)
+
+	assert.Panics(t, func() { e.AddTrailingAD(trailer) })
+	assert.Panics(t, func() { newGCMEncrypter(block, nonce, header).AddTrailingAD(trailer) })
+}
+
+func TestCombineGHASH(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	g := newGCM(block)
+
+	data := make([]byte, 200)
+	for i := range data {
+		data[i] = byte(i * 5)
+	}
+
+	for _, lengths := range [][]int{
+		{},
+		{16},
+		{0, 16},
+		{1, 15, 16, 17},
+		{33, 0, 64, 7},
+	} {
+		var states []gcmFieldElement
+		var want gcmFieldElement
+		offset := 0
+		for _, n := range lengths {
+			message := data[offset : offset+n]
+			offset += n
+
+			var state gcmFieldElement
+			g.update(&state, message)
+			states = append(states, state)
+
+			g.update(&want, message)
+		}
+
+		assert.Equal(t, want, g.CombineGHASH(states, lengths), "lengths %v", lengths)
+	}
+
+	assert.Panics(t, func() { g.CombineGHASH(make([]gcmFieldElement, 2), []int{1}) })
+}
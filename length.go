@@ -0,0 +1,43 @@
+package uncheckedgcm
+
+import "encoding/binary"
+
+// LengthBlockFunc encodes the bit lengths of the additional data and of the
+// encrypted data into the final block hashed before the tag is masked.
+type LengthBlockFunc func(additionalDataBits, dataBits uint64) [gcmBlockSize]byte
+
+// StandardLengthBlock is the GCM length block: both lengths as big-endian
+// 64-bit integers.
+func StandardLengthBlock(additionalDataBits, dataBits uint64) [gcmBlockSize]byte {
+	var block [gcmBlockSize]byte
+	binary.BigEndian.PutUint64(block[:8], additionalDataBits)
+	binary.BigEndian.PutUint64(block[8:], dataBits)
+	return block
+}
+
+// SetLengthBlock replaces the encoding of the final length block, for peers
+// that deviate from GCM. Only the finalization step is affected; the data is
+// hashed as usual. Passing nil restores StandardLengthBlock.
+func (g *gcm) SetLengthBlock(f LengthBlockFunc) {
+	g.lengthBlock = f
+}
+
+// FinalizeWith computes the tag with the given bit lengths in the length
+// block instead of the lengths of the data actually processed, and
+// finalizes like Tag.
+//
+// The length block is what stops data from being moved between the
+// additional data and the message, or extended with zero bytes. Whatever
+// lengths are passed here must be authenticated by the protocol some other
+// way, or a peer checking against the real lengths must reject the tag.
+func (g *gcm) FinalizeWith(additionalDataBits, dataBits uint64) [gcmTagSize]byte {
+	g.mustBeLive()
+	if g.finalized {
+		panic(ErrFinalized)
+	}
+
+	g.flush()
+	g.foldLazyAD()
+	g.finalize(g.tagBits(&g.ghash, additionalDataBits, dataBits))
+	return g.finalTag
+}
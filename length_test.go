@@ -0,0 +1,101 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// littleEndian32LengthBlock packs both lengths as little-endian 32-bit words
+// at the start of the block.
+func littleEndian32LengthBlock(additionalDataBits, dataBits uint64) [gcmBlockSize]byte {
+	var block [gcmBlockSize]byte
+	binary.LittleEndian.PutUint32(block[:4], uint32(additionalDataBits))
+	binary.LittleEndian.PutUint32(block[4:8], uint32(dataBits))
+	return block
+}
+
+func TestStandardLengthBlock(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	additionalData := []byte("header")
+
+	want := newGCMEncrypter(block, nonce, additionalData)
+	want.Encrypt(nil, decryptedPacket)
+
+	gcm := newGCMEncrypter(block, nonce, additionalData)
+	gcm.SetLengthBlock(StandardLengthBlock)
+	gcm.Encrypt(nil, decryptedPacket)
+
+	assert.Equal(t, want.Tag(), gcm.Tag())
+}
+
+func TestCustomLengthBlock(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	additionalData := []byte("header")
+
+	enc := newGCMEncrypter(block, nonce, additionalData)
+	enc.SetLengthBlock(littleEndian32LengthBlock)
+	ciphertext := enc.Encrypt(nil, decryptedPacket)
+	tag := enc.Tag()
+
+	// Recompute the tag independently with the reference GHASH.
+	ref := &referenceGHASH{h: enc.productTable[reverseBits(1)]}
+	ref.Update(additionalData)
+	ref.Update(ciphertext)
+	lengths := littleEndian32LengthBlock(uint64(len(additionalData))*8, uint64(len(ciphertext))*8)
+	assert.Equal(t, [gcmBlockSize]byte{48, 0, 0, 0, 160}, lengths)
+	ref.Update(lengths[:])
+
+	want := ref.Sum()
+	for i := range want {
+		want[i] ^= enc.tagMask[i]
+	}
+	assert.Equal(t, want, tag)
+
+	dec := newGCMDecrypter(block, nonce, additionalData)
+	dec.SetLengthBlock(littleEndian32LengthBlock)
+	_, err = dec.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, dec.Verify(tag[:]))
+
+	standard := newGCMDecrypter(block, nonce, additionalData)
+	_, err = standard.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, standard.Verify(tag[:]), ErrOpen)
+}
+
+func TestFinalizeWith(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	ad := []byte("header")
+
+	want := newGCMEncrypter(block, nonce, ad)
+	ciphertext := want.Encrypt(nil, decryptedPacket)
+	wantTag := want.Tag()
+
+	e := newGCMEncrypter(block, nonce, ad)
+	e.Encrypt(nil, decryptedPacket)
+	tag := e.FinalizeWith(uint64(len(ad))*8, uint64(len(decryptedPacket))*8)
+	assert.Equal(t, wantTag, tag)
+	assert.Equal(t, tag, e.Tag())
+	assert.PanicsWithValue(t, ErrFinalized, func() { e.FinalizeWith(0, 0) })
+
+	// Authenticating two extra bytes of zero padding that were never
+	// processed gives a different tag, which a decrypter can reproduce.
+	e = newGCMEncrypter(block, nonce, ad)
+	e.Encrypt(nil, decryptedPacket)
+	padded := e.FinalizeWith(uint64(len(ad))*8, uint64(len(decryptedPacket)+2)*8)
+	assert.NotEqual(t, wantTag, padded)
+
+	d := newGCMDecrypter(block, nonce, ad)
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, padded, d.FinalizeWith(uint64(len(ad))*8, uint64(len(decryptedPacket)+2)*8))
+}
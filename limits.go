@@ -0,0 +1,55 @@
+package uncheckedgcm
+
+// shortTagLimit is a row of SP 800-38D Appendix C: packets whose combined
+// ciphertext and additional data length is at most 1<<lengthLog2 bytes may
+// be decrypted at most 1<<invocationsLog2 times under one key.
+type shortTagLimit struct {
+	lengthLog2, invocationsLog2 uint
+}
+
+var (
+	// tag32Limits is Table 8 of SP 800-38D Appendix C.
+	tag32Limits = []shortTagLimit{
+		{1, 22}, {2, 20}, {3, 18}, {4, 16}, {5, 14}, {6, 12},
+		{7, 10}, {8, 8}, {9, 6}, {10, 4}, {11, 2},
+	}
+
+	// tag64Limits is Table 9 of SP 800-38D Appendix C.
+	tag64Limits = []shortTagLimit{
+		{15, 32}, {17, 29}, {19, 26}, {21, 23}, {23, 20},
+		{25, 17}, {27, 14}, {29, 11}, {31, 8},
+	}
+)
+
+// MaxMessages returns the number of messages that NIST SP 800-38D permits
+// under a single key for the given tag length in bits, when no message's
+// combined ciphertext and additional data exceeds maxLength bytes.
+//
+// For tags of 96 bits or more the limit is the 2^32 invocations allowed when
+// nonces are not 96-bit deterministic IVs (section 8.3), which covers this
+// package's 16-byte nonces, and maxLength does not matter. For 32- and
+// 64-bit tags the limit on decryptions is taken from the tables in Appendix
+// C, using the smallest tabulated length that is at least maxLength.
+// MaxMessages returns 0 if maxLength is longer than the tables allow, or for
+// tag lengths the standard does not allow.
+func MaxMessages(tagBits int, maxLength uint64) uint64 {
+	switch tagBits {
+	case 96, 104, 112, 120, 128:
+		return 1 << 32
+	case 64:
+		return shortTagMaxMessages(tag64Limits, maxLength)
+	case 32:
+		return shortTagMaxMessages(tag32Limits, maxLength)
+	default:
+		return 0
+	}
+}
+
+func shortTagMaxMessages(limits []shortTagLimit, maxLength uint64) uint64 {
+	for _, limit := range limits {
+		if maxLength <= 1<<limit.lengthLog2 {
+			return 1 << limit.invocationsLog2
+		}
+	}
+	return 0
+}
@@ -0,0 +1,54 @@
+package uncheckedgcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaxMessages(t *testing.T) {
+	assert.Equal(t, uint64(1<<32), MaxMessages(128, 1<<30))
+	assert.Equal(t, uint64(1<<32), MaxMessages(96, 0))
+
+	// SP 800-38D Appendix C, Tables 8 and 9: maximum combined length in
+	// bytes and maximum invocations.
+	for _, tc := range []struct {
+		tagBits   int
+		maxLength uint64
+		want      uint64
+	}{
+		{32, 1 << 1, 1 << 22},
+		{32, 1 << 2, 1 << 20},
+		{32, 1 << 3, 1 << 18},
+		{32, 1 << 4, 1 << 16},
+		{32, 1 << 5, 1 << 14},
+		{32, 1 << 6, 1 << 12},
+		{32, 1 << 7, 1 << 10},
+		{32, 1 << 8, 1 << 8},
+		{32, 1 << 9, 1 << 6},
+		{32, 1 << 10, 1 << 4},
+		{32, 1 << 11, 1 << 2},
+		{64, 1 << 15, 1 << 32},
+		{64, 1 << 17, 1 << 29},
+		{64, 1 << 19, 1 << 26},
+		{64, 1 << 21, 1 << 23},
+		{64, 1 << 23, 1 << 20},
+		{64, 1 << 25, 1 << 17},
+		{64, 1 << 27, 1 << 14},
+		{64, 1 << 29, 1 << 11},
+		{64, 1 << 31, 1 << 8},
+	} {
+		assert.Equal(t, tc.want, MaxMessages(tc.tagBits, tc.maxLength), "t=%d, length %d", tc.tagBits, tc.maxLength)
+	}
+
+	// Lengths between rows round up to the next row.
+	assert.Equal(t, uint64(1<<8), MaxMessages(32, 200))
+	assert.Equal(t, uint64(1<<32), MaxMessages(64, 0))
+
+	// Longer than the tables allow.
+	assert.Zero(t, MaxMessages(32, 1<<11+1))
+	assert.Zero(t, MaxMessages(64, 1<<31+1))
+
+	assert.Zero(t, MaxMessages(0, 16))
+	assert.Zero(t, MaxMessages(100, 16))
+}
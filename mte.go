@@ -0,0 +1,26 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// newGCMEncrypterMtE returns an encrypter in MAC-then-encrypt mode: GHASH
+// runs over the plaintext instead of the ciphertext. This is not GCM and its
+// tags do not interoperate with it; it exists for legacy protocols that
+// authenticate plaintext with a GCM-shaped construction. The standard
+// constructors always authenticate ciphertext.
+//
+// MAC-then-encrypt gives up GCM's ability to reject a forgery before
+// decrypting it, so a decrypter's output must not be acted on until Verify
+// succeeds.
+func newGCMEncrypterMtE(cipher cipher.Block, nonce, additionalData []byte) *Encrypter {
+	g := newGCM(cipher)
+	g.macPlaintext = true
+	return g.newEncrypter(nonce, additionalData)
+}
+
+// newGCMDecrypterMtE returns a decrypter for messages from
+// newGCMEncrypterMtE. GHASH runs over the decrypted plaintext.
+func newGCMDecrypterMtE(cipher cipher.Block, nonce, additionalData []byte) *Decrypter {
+	g := newGCM(cipher)
+	g.macPlaintext = true
+	return g.newDecrypter(nonce, additionalData)
+}
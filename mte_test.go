@@ -0,0 +1,78 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMtEVectors(t *testing.T) {
+	// Test case 4: the standard mode must reproduce the published tag, and
+	// MAC-then-encrypt the same ciphertext under a different tag.
+	tc := gcmTestVectors()[3]
+	k, n, ad := decodeHex(t, tc.key), decodeHex(t, tc.nonce), decodeHex(t, tc.additionalData)
+	plaintext, ciphertext := decodeHex(t, tc.plaintext), decodeHex(t, tc.ciphertext)
+
+	block, err := aes.NewCipher(k)
+	assert.Nil(t, err)
+
+	etm := newGCMEncrypter(block, n, ad)
+	assert.Equal(t, ciphertext, etm.Encrypt(nil, plaintext))
+	etmTag := etm.Tag()
+	assert.Equal(t, decodeHex(t, tc.tag), etmTag[:])
+
+	mte := newGCMEncrypterMtE(block, n, ad)
+	assert.Equal(t, ciphertext, mte.Encrypt(nil, plaintext))
+	mteTag := mte.Tag()
+	assert.Equal(t, decodeHex(t, "13f7e9df5690e202428b4bfa5dd177f3"), mteTag[:])
+
+	// The MtE tag is the GCM tag of a message whose ciphertext is the
+	// plaintext.
+	reference := newGCMDecrypter(block, n, ad)
+	_, err = reference.Decrypt(nil, plaintext)
+	assert.Nil(t, err)
+	assert.Equal(t, reference.Tag(), mteTag)
+
+	dec := newGCMDecrypterMtE(block, n, ad)
+	out, err := dec.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, out)
+	assert.Nil(t, dec.Verify(mteTag[:]))
+
+	dec = newGCMDecrypterMtE(block, n, ad)
+	_, err = dec.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.NotNil(t, dec.Verify(etmTag[:]))
+}
+
+func TestMtEInPlace(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	buf := append([]byte{}, decryptedPacket...)
+	g := newGCMEncrypterMtE(block, nonce, nil)
+	g.Encrypt(buf[:0], buf)
+	assert.Equal(t, encryptedPacket, buf)
+	mteTag := g.Tag()
+
+	d := newGCMDecrypterMtE(block, nonce, nil)
+	_, err = d.Decrypt(buf[:0], buf)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, buf)
+	assert.Nil(t, d.Verify(mteTag[:]))
+
+	reference := newGCMDecrypter(block, nonce, nil)
+	_, err = reference.Decrypt(nil, decryptedPacket)
+	assert.Nil(t, err)
+	assert.Equal(t, reference.Tag(), mteTag)
+}
+
+func TestReencryptRejectsMtE(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	assert.Panics(t, func() {
+		newGCMEncrypter(block, nonce, nil).Reencrypt(nil, newGCMDecrypterMtE(block, nonce, nil), encryptedPacket)
+	})
+}
@@ -0,0 +1,36 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGCMTestVectors(t *testing.T) {
+	for i, tv := range gcmTestVectors() {
+		block, err := aes.NewCipher(decodeHex(t, tv.key))
+		assert.Nil(t, err)
+
+		nonce := decodeHex(t, tv.nonce)
+		plaintext := decodeHex(t, tv.plaintext)
+		additionalData := decodeHex(t, tv.additionalData)
+		ciphertext := decodeHex(t, tv.ciphertext)
+		tag := decodeHex(t, tv.tag)
+
+		enc := newGCMEncrypter(block, nonce, additionalData)
+		gotCiphertext := enc.Encrypt(nil, plaintext)
+		gotTag := enc.Tag()
+
+		assert.Equal(t, tv.ciphertext, hex.EncodeToString(gotCiphertext), "test case %d", i+1)
+		assert.Equal(t, tv.tag, hex.EncodeToString(gotTag[:]), "test case %d", i+1)
+
+		dec := newGCMDecrypter(block, nonce, additionalData)
+		gotPlaintext, err := dec.Decrypt(nil, ciphertext)
+		assert.Nil(t, err)
+
+		assert.Equal(t, tv.plaintext, hex.EncodeToString(gotPlaintext), "test case %d", i+1)
+		assert.Nil(t, dec.Verify(tag), "test case %d", i+1)
+	}
+}
@@ -0,0 +1,27 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// newGCMEncrypterAuthenticatedNonce is like newGCMEncrypter but also
+// authenticates the nonce as additional data, ahead of additionalData. The
+// tag is that of standard GCM with additional data nonce||additionalData.
+//
+// GCM already binds the nonce, since it determines both the keystream and
+// the tag mask, so a swapped nonce fails verification without this. Hashing
+// it explicitly is for protocols that specify it or want the binding to
+// survive changes to how the nonce is used.
+func newGCMEncrypterAuthenticatedNonce(cipher cipher.Block, nonce, additionalData []byte) *Encrypter {
+	return newGCMEncrypter(cipher, nonce, nonceAD(nonce, additionalData))
+}
+
+// newGCMDecrypterAuthenticatedNonce is the decrypting counterpart of
+// newGCMEncrypterAuthenticatedNonce.
+func newGCMDecrypterAuthenticatedNonce(cipher cipher.Block, nonce, additionalData []byte) *Decrypter {
+	return newGCMDecrypter(cipher, nonce, nonceAD(nonce, additionalData))
+}
+
+func nonceAD(nonce, additionalData []byte) []byte {
+	ad := make([]byte, 0, len(nonce)+len(additionalData))
+	ad = append(ad, nonce...)
+	return append(ad, additionalData...)
+}
@@ -0,0 +1,35 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuthenticatedNonce(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypterAuthenticatedNonce(block, nonce, []byte("ad"))
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	reference := newGCMEncrypter(block, nonce, append(append([]byte{}, nonce...), "ad"...))
+	assert.Equal(t, reference.Encrypt(nil, decryptedPacket), ciphertext)
+	assert.Equal(t, reference.Tag(), tag)
+
+	d := newGCMDecrypterAuthenticatedNonce(block, nonce, []byte("ad"))
+	plaintext, err := d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+	assert.Nil(t, d.VerifyArray(tag))
+
+	// A swapped nonce on the wire fails verification.
+	swapped := append([]byte{}, nonce...)
+	swapped[0] ^= 1
+	d = newGCMDecrypterAuthenticatedNonce(block, swapped, []byte("ad"))
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrOpen, d.VerifyArray(tag))
+}
@@ -0,0 +1,15 @@
+package uncheckedgcm
+
+// Observer receives notifications about decrypter activity. It is never given
+// key material, plaintext or tags, only outcomes and byte counts.
+type Observer interface {
+	// OnVerify is called each time Verify completes with whether the tag
+	// matched and the number of ciphertext bytes it covered.
+	OnVerify(ok bool, bytesProcessed uint64)
+}
+
+// SetObserver registers o to be notified of verification outcomes. Passing
+// nil removes any observer.
+func (g *Decrypter) SetObserver(o Observer) {
+	g.observer = o
+}
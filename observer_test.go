@@ -0,0 +1,47 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type verifyEvent struct {
+	ok             bool
+	bytesProcessed uint64
+}
+
+type recordingObserver struct {
+	events []verifyEvent
+}
+
+func (o *recordingObserver) OnVerify(ok bool, bytesProcessed uint64) {
+	o.events = append(o.events, verifyEvent{ok, bytesProcessed})
+}
+
+func TestObserver(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var observer recordingObserver
+
+	gcm := newGCMDecrypter(block, nonce, nil)
+	gcm.SetObserver(&observer)
+
+	_, err = gcm.Decrypt(nil, []byte{0, 0, 0, 0})
+	assert.Nil(t, err)
+	assert.Nil(t, gcm.Verify(tag[:]))
+
+	gcm = newGCMDecrypter(block, nonce, nil)
+	gcm.SetObserver(&observer)
+
+	_, err = gcm.Decrypt(nil, encryptedPacket)
+	assert.Nil(t, err)
+	assert.ErrorIs(t, gcm.Verify(tag[:]), ErrOpen)
+
+	assert.Equal(t, []verifyEvent{
+		{true, 4},
+		{false, uint64(len(encryptedPacket))},
+	}, observer.events)
+}
@@ -0,0 +1,86 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+)
+
+// maxPacketNumber is the largest QUIC packet number, 2^62-1.
+const maxPacketNumber = 1<<62 - 1
+
+var errPacketSize = errors.New("gcm: packet too short")
+
+// xorNonce derives the nonce for sequence number index by XORing it into
+// the last eight bytes of baseNonce, as TLS 1.3 and QUIC do for records.
+func xorNonce(baseNonce []byte, index uint64) []byte {
+	nonce := append([]byte(nil), baseNonce...)
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], index)
+	for i := range counter {
+		nonce[len(nonce)-len(counter)+i] ^= counter[i]
+	}
+
+	return nonce
+}
+
+// PacketProtector seals and opens QUIC-style packets. The header is
+// authenticated as additional data and each packet's nonce is the static IV
+// XORed with its packet number, following RFC 9001 Section 5.3.
+//
+// Packet numbers must never repeat under the same key and IV; the protector
+// does not track which have been used.
+type PacketProtector struct {
+	cipher cipher.Block
+	iv     []byte
+}
+
+func newPacketProtector(cipher cipher.Block, iv []byte) *PacketProtector {
+	if len(iv) < 8 {
+		panic("gcm: packet protection IV must be at least 8 bytes")
+	}
+
+	return &PacketProtector{
+		cipher: cipher,
+		iv:     append([]byte(nil), iv...),
+	}
+}
+
+// Nonce returns the nonce used to protect packet number pn.
+func (p *PacketProtector) Nonce(pn uint64) []byte {
+	if pn > maxPacketNumber {
+		panic("gcm: packet number out of range")
+	}
+	return xorNonce(p.iv, pn)
+}
+
+// Protect appends header||ciphertext||tag to dst.
+func (p *PacketProtector) Protect(dst, header []byte, pn uint64, payload []byte) []byte {
+	g := newGCMEncrypter(p.cipher, p.Nonce(pn), header)
+
+	ret := append(dst, header...)
+	ret = g.Encrypt(ret, payload)
+	tag := g.Tag()
+	return append(ret, tag[:]...)
+}
+
+// Unprotect verifies a packet whose first headerLen bytes are the header and
+// appends the payload to dst. Nothing is appended unless the tag matches.
+func (p *PacketProtector) Unprotect(dst, packet []byte, headerLen int, pn uint64) ([]byte, error) {
+	if headerLen < 0 || len(packet)-headerLen < gcmTagSize {
+		return nil, errPacketSize
+	}
+
+	header := packet[:headerLen]
+	ciphertext := packet[headerLen : len(packet)-gcmTagSize]
+	tag := packet[len(packet)-gcmTagSize:]
+
+	g := newGCMDecrypter(p.cipher, p.Nonce(pn), header)
+	payload, err := g.OpenDetached(ciphertext, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(dst, payload...), nil
+}
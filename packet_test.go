@@ -0,0 +1,65 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestXORNonce(t *testing.T) {
+	base := make([]byte, 12)
+	base[11] = 0xff
+
+	assert.Equal(t, base, xorNonce(base, 0))
+	assert.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0xfe}, xorNonce(base, 0x101))
+}
+
+func TestPacketNonce(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// Client Initial IV and packet number from RFC 9001 Appendix A.
+	p := newPacketProtector(block, decodeHex(t, "fa044b2f42a3fd3b46fb255c"))
+	assert.Equal(t, "fa044b2f42a3fd3b46fb255e", hex.EncodeToString(p.Nonce(2)))
+	assert.Equal(t, "fa044b2f42a3fd3b46fb255c", hex.EncodeToString(p.Nonce(0)))
+	assert.Equal(t, "fa044b2f7d5c02c4b904daa3", hex.EncodeToString(p.Nonce(maxPacketNumber)))
+
+	assert.Panics(t, func() { p.Nonce(1 << 62) })
+}
+
+func TestPacketProtector(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	iv := decodeHex(t, "fa044b2f42a3fd3b46fb255c")
+	p := newPacketProtector(block, iv)
+
+	header := []byte{0xc3, 0x00, 0x00, 0x00, 0x01, 0x02}
+	payload := []byte("quic-like datagram")
+
+	packet := p.Protect(nil, header, 7, payload)
+	assert.Equal(t, header, packet[:len(header)])
+
+	// With a 12-byte IV the packet is standard AES-GCM.
+	aead, err := cipher.NewGCM(block)
+	assert.Nil(t, err)
+	want := aead.Seal(nil, p.Nonce(7), payload, header)
+	assert.Equal(t, want, packet[len(header):])
+
+	got, err := p.Unprotect(nil, packet, len(header), 7)
+	assert.Nil(t, err)
+	assert.Equal(t, payload, got)
+
+	_, err = p.Unprotect(nil, packet, len(header), 8)
+	assert.ErrorIs(t, err, ErrOpen)
+
+	packet[0] ^= 1
+	_, err = p.Unprotect(nil, packet, len(header), 7)
+	assert.ErrorIs(t, err, ErrOpen)
+
+	_, err = p.Unprotect(nil, packet[:len(header)+gcmTagSize-1], len(header), 7)
+	assert.ErrorIs(t, err, errPacketSize)
+}
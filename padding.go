@@ -0,0 +1,25 @@
+package uncheckedgcm
+
+import "crypto/subtle"
+
+// SetConstantTimePadding makes the zero-padding of partial blocks before
+// they are hashed touch all 16 bytes of the block without branching on how
+// many bytes the tail holds. GCM lengths are public, so this is only for
+// hardened builds that want no length-dependent control flow there. The
+// output is the same either way.
+func (g *gcm) SetConstantTimePadding(on bool) {
+	g.constantTimePad = on
+}
+
+// padConstantTime sets block to tail followed by zeros. tail must hold
+// between 1 and 16 bytes and may alias block.
+func padConstantTime(block *[gcmBlockSize]byte, tail []byte) {
+	n := len(tail)
+	for i := 0; i < gcmBlockSize; i++ {
+		// keep is 1 for i < n. Past the tail, read tail[0] so that every
+		// iteration performs the same in-bounds load, then mask it off.
+		keep := 1 - subtle.ConstantTimeLessOrEq(n, i)
+		j := subtle.ConstantTimeSelect(keep, i, 0)
+		block[i] = tail[j] & byte(-keep)
+	}
+}
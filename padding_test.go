@@ -0,0 +1,59 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPadConstantTime(t *testing.T) {
+	data := []byte("0123456789abcdef")
+
+	for n := 1; n <= gcmBlockSize; n++ {
+		var want [gcmBlockSize]byte
+		copy(want[:], data[:n])
+
+		var got [gcmBlockSize]byte
+		for i := range got {
+			got[i] = 0xaa
+		}
+		padConstantTime(&got, data[:n])
+		assert.Equal(t, want, got, "tail %d", n)
+
+		// In place, as flush uses it.
+		aliased := [gcmBlockSize]byte(data)
+		padConstantTime(&aliased, aliased[:n])
+		assert.Equal(t, want, aliased, "aliased tail %d", n)
+	}
+}
+
+func TestConstantTimePadding(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	data := make([]byte, 2*gcmBlockSize)
+	for i := range data {
+		data[i] = byte(i + 1)
+	}
+
+	for tail := 0; tail < gcmBlockSize; tail++ {
+		ad := data[:gcmBlockSize+tail]
+		plaintext := data[:gcmBlockSize+(tail+5)%gcmBlockSize]
+
+		want := newGCMEncrypter(block, nonce, ad)
+		wantCiphertext := want.Encrypt(nil, plaintext)
+
+		e := newGCMEncrypter(block, nonce, nil)
+		e.SetConstantTimePadding(true)
+		e.AddAdditionalData(ad)
+		assert.Equal(t, wantCiphertext, e.Encrypt(nil, plaintext))
+		assert.Equal(t, want.Tag(), e.Tag(), "tail %d", tail)
+
+		// update pads per call, which the lazy path exercises.
+		lazy := newLazyGCMEncrypter(block, nonce, ad)
+		lazy.SetConstantTimePadding(true)
+		lazy.Encrypt(nil, plaintext)
+		assert.Equal(t, want.Tag(), lazy.Tag(), "lazy tail %d", tail)
+	}
+}
@@ -0,0 +1,72 @@
+package uncheckedgcm
+
+import (
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+var errPatchRange = errors.New("gcm: patch outside the encrypted data")
+
+// Patch replaces the plaintext at offset in the message g has encrypted so
+// far with newPlaintext and returns the ciphertext to write over
+// oldCiphertext, the ciphertext currently stored at that offset. Only the
+// patched range is encrypted again; the running GHASH is corrected in place,
+// so Tag afterwards returns the tag of the edited message.
+//
+// Changing a data block by D changes the final GHASH by D·H^k, where k is
+// the number of blocks hashed from that block onwards. The difference of two
+// ciphertexts under the same keystream equals the difference of their
+// plaintexts, so this holds in MAC-then-encrypt mode too.
+//
+// oldCiphertext must be the ciphertext g produced for that range, or the tag
+// will be wrong. Patch must be called before Tag.
+func (g *Encrypter) Patch(offset int, newPlaintext, oldCiphertext []byte) ([]byte, error) {
+	g.mustBeLive()
+	if g.finalized {
+		return nil, ErrFinalized
+	}
+	if offset < 0 || len(newPlaintext) != len(oldCiphertext) ||
+		uint64(offset)+uint64(len(newPlaintext)) > g.plaintextNb {
+		return nil, errPatchRange
+	}
+	if len(newPlaintext) == 0 {
+		return []byte{}, nil
+	}
+
+	first := offset / gcmBlockSize
+	last := (offset + len(newPlaintext) + gcmBlockSize - 1) / gcmBlockSize
+	skip := offset % gcmBlockSize
+
+	counter := g.initialCounter
+	ctr := binary.BigEndian.Uint32(counter[gcmBlockSize-4:]) + uint32(first)
+	binary.BigEndian.PutUint32(counter[gcmBlockSize-4:], ctr)
+
+	keystream := make([]byte, (last-first)*gcmBlockSize)
+	g.keystream(keystream, &counter)
+
+	newCiphertext := make([]byte, len(newPlaintext))
+	subtle.XORBytes(newCiphertext, newPlaintext, keystream[skip:])
+	clear(keystream)
+
+	// Reuse the keystream buffer for the block-aligned difference.
+	delta := keystream
+	subtle.XORBytes(delta[skip:], newCiphertext, oldCiphertext)
+
+	// Blocks before hashed are already in the GHASH state; a final partial
+	// block is still held in ghashTail and can be patched directly.
+	hashed := int(g.plaintextNb / gcmBlockSize)
+	full := min(last, hashed) - first
+	if full > 0 {
+		var d gcmFieldElement
+		g.updateBlocks(&d, delta[:full*gcmBlockSize])
+		power := g.field.pow(g.productTable[reverseBits(1)], uint64(hashed-first-full))
+		d = g.field.mul(&d, &power)
+		g.ghash = gcmAdd(&g.ghash, &d)
+	}
+	if last > hashed {
+		subtle.XORBytes(g.ghashTail[:g.ghashTailNb], g.ghashTail[:g.ghashTailNb], delta[(hashed-first)*gcmBlockSize:])
+	}
+
+	return newCiphertext, nil
+}
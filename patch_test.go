@@ -0,0 +1,84 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPatch(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	rng := rand.New(rand.NewSource(1))
+	ad := []byte("header")
+
+	for _, size := range []int{1, 15, 16, 17, 64, 100} {
+		for i := 0; i < 20; i++ {
+			plaintext := make([]byte, size)
+			rng.Read(plaintext)
+
+			offset := rng.Intn(size)
+			patch := make([]byte, rng.Intn(size-offset+1))
+			rng.Read(patch)
+
+			g := newGCMEncrypter(block, nonce, ad)
+			ciphertext := g.Encrypt(nil, plaintext)
+
+			newCiphertext, err := g.Patch(offset, patch, ciphertext[offset:offset+len(patch)])
+			assert.Nil(t, err)
+			copy(ciphertext[offset:], newCiphertext)
+
+			copy(plaintext[offset:], patch)
+			reference := newGCMEncrypter(block, nonce, ad)
+			assert.Equal(t, reference.Encrypt(nil, plaintext), ciphertext)
+			assert.Equal(t, reference.Tag(), g.Tag())
+		}
+	}
+}
+
+func TestPatchModes(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 50)
+	edited := append([]byte{}, plaintext...)
+	copy(edited[10:], "patched!")
+
+	for name, newEnc := range map[string]func() *Encrypter{
+		"lazy": func() *Encrypter { return newLazyGCMEncrypter(block, nonce, []byte("ad")) },
+		"mte":  func() *Encrypter { return newGCMEncrypterMtE(block, nonce, []byte("ad")) },
+	} {
+		t.Run(name, func(t *testing.T) {
+			g := newEnc()
+			ciphertext := g.Encrypt(nil, plaintext)
+			_, err := g.Patch(10, edited[10:18], ciphertext[10:18])
+			assert.Nil(t, err)
+
+			reference := newEnc()
+			reference.Encrypt(nil, edited)
+			assert.Equal(t, reference.Tag(), g.Tag())
+		})
+	}
+}
+
+func TestPatchErrors(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	g := newGCMEncrypter(block, nonce, nil)
+	ciphertext := g.Encrypt(nil, decryptedPacket)
+
+	_, err = g.Patch(-1, []byte{0}, ciphertext[:1])
+	assert.Equal(t, errPatchRange, err)
+	_, err = g.Patch(19, []byte{0, 0}, ciphertext[18:])
+	assert.Equal(t, errPatchRange, err)
+	_, err = g.Patch(0, []byte{0}, ciphertext[:2])
+	assert.Equal(t, errPatchRange, err)
+
+	g.Tag()
+	_, err = g.Patch(0, []byte{0}, ciphertext[:1])
+	assert.Equal(t, ErrFinalized, err)
+}
@@ -0,0 +1,47 @@
+package uncheckedgcm
+
+const (
+	// pipelineThreshold is the smallest Encrypt call that is split between
+	// two goroutines. Below it the goroutine and channel cost more than
+	// the overlap saves.
+	pipelineThreshold = 64 * 1024
+
+	// pipelineChunk is how much ciphertext is handed from the keystream
+	// goroutine to the GHASH goroutine at a time.
+	pipelineChunk = 8 * 1024
+)
+
+// encryptPipelined encrypts plaintext into out in chunks on a second
+// goroutine while the calling goroutine hashes each chunk of ciphertext as
+// soon as it is ready, overlapping the block cipher with GHASH. The two
+// sides touch disjoint parts of g: the counter and keystream buffers, and
+// the GHASH state. The output and tag are identical to the serial path.
+//
+// A panic from the block cipher is re-raised on the calling goroutine.
+func (g *gcm) encryptPipelined(out, plaintext []byte) {
+	ready := make(chan int, 4)
+
+	var failure any
+	go func() {
+		defer func() {
+			failure = recover()
+			close(ready)
+		}()
+
+		for i := 0; i < len(plaintext); i += pipelineChunk {
+			end := min(i+pipelineChunk, len(plaintext))
+			g.counterCrypt(out[i:end], plaintext[i:end], &g.counter)
+			ready <- end
+		}
+	}()
+
+	start := 0
+	for end := range ready {
+		g.updateStream(out[start:end])
+		start = end
+	}
+
+	if failure != nil {
+		panic(failure)
+	}
+}
@@ -0,0 +1,87 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptPipelinedMatchesSerial(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, block := range []cipher.Block{aesBlock, &bulkBlock{Block: aesBlock}} {
+		for _, size := range []int{pipelineThreshold, pipelineThreshold + 1, 3*pipelineThreshold + 13} {
+			plaintext := make([]byte, size)
+			for i := range plaintext {
+				plaintext[i] = byte(i * 13)
+			}
+
+			// Start mid-block so leftover keystream feeds the pipeline.
+			e := newGCMEncrypter(block, nonce, []byte("ad"))
+			got := e.Encrypt(nil, plaintext[:5])
+			got = e.Encrypt(got, plaintext[5:])
+
+			serial := newGCMEncrypter(block, nonce, []byte("ad"))
+			want := serial.Encrypt(nil, plaintext[:5])
+			out := make([]byte, size-5)
+			serial.counterCrypt(out, plaintext[5:], &serial.counter)
+			serial.updateStream(out)
+			serial.plaintextNb += uint64(len(out))
+			want = append(want, out...)
+
+			assert.Equal(t, want, got, "size %d", size)
+			assert.Equal(t, serial.Tag(), e.Tag(), "size %d", size)
+		}
+	}
+}
+
+// panickingBlock panics on every call after the first limit.
+type panickingBlock struct {
+	cipher.Block
+	limit, calls int
+}
+
+func (b *panickingBlock) Encrypt(dst, src []byte) {
+	b.calls++
+	if b.calls > b.limit {
+		panic("block failure")
+	}
+	b.Block.Encrypt(dst, src)
+}
+
+func TestEncryptPipelinedPanic(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(&panickingBlock{Block: aesBlock, limit: 100}, nonce, nil)
+	assert.PanicsWithValue(t, "block failure", func() {
+		e.Encrypt(nil, make([]byte, pipelineThreshold))
+	})
+}
+
+func BenchmarkEncryptPipelined(b *testing.B) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	buf := make([]byte, 1024*1024)
+	b.Run("serial", func(b *testing.B) {
+		e := newGCMEncrypter(block, nonce, nil)
+		b.SetBytes(int64(len(buf)))
+		for i := 0; i < b.N; i++ {
+			e.counterCrypt(buf, buf, &e.counter)
+			e.updateStream(buf)
+		}
+	})
+	b.Run("pipelined", func(b *testing.B) {
+		e := newGCMEncrypter(block, nonce, nil)
+		b.SetBytes(int64(len(buf)))
+		for i := 0; i < b.N; i++ {
+			e.encryptPipelined(buf, buf)
+		}
+	})
+}
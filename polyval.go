@@ -0,0 +1,63 @@
+package uncheckedgcm
+
+import (
+	"encoding/binary"
+	"slices"
+)
+
+// POLYVAL, used by AES-GCM-SIV, works in the same field as GHASH but with
+// the bits of each block in the opposite order and an extra factor of x^-128
+// in every product. RFC 8452, Appendix A, shows that
+//
+//	dot(a, b) = ByteReverse(GHASH-multiply(ByteReverse(a), mulX_GHASH(ByteReverse(b))))
+//
+// so POLYVAL can reuse the GHASH multiply: byte-reverse the operands and
+// multiply the key by x once, which is gcmDouble.
+
+// polyvalElement converts a POLYVAL block to the equivalent GHASH field
+// element.
+func polyvalElement(block [gcmBlockSize]byte) gcmFieldElement {
+	slices.Reverse(block[:])
+	return gcmFieldElement{
+		binary.BigEndian.Uint64(block[:8]),
+		binary.BigEndian.Uint64(block[8:]),
+	}
+}
+
+// polyvalBlock is the inverse of polyvalElement.
+func polyvalBlock(x gcmFieldElement) [gcmBlockSize]byte {
+	var block [gcmBlockSize]byte
+	binary.BigEndian.PutUint64(block[:8], x.low)
+	binary.BigEndian.PutUint64(block[8:], x.high)
+	slices.Reverse(block[:])
+	return block
+}
+
+// polyvalSubkey returns the GHASH subkey equivalent to the POLYVAL key h.
+// A gcm whose product table is built from it with setSubkey multiplies
+// POLYVAL blocks by h with the table-driven mul; see polyvalMulH.
+func polyvalSubkey(h [gcmBlockSize]byte) [gcmBlockSize]byte {
+	x := polyvalElement(h)
+	x = gcmDouble(&x)
+
+	var key [gcmBlockSize]byte
+	binary.BigEndian.PutUint64(key[:8], x.low)
+	binary.BigEndian.PutUint64(key[8:], x.high)
+	return key
+}
+
+// polyvalMul returns dot(a, b), the POLYVAL product of a and b.
+func polyvalMul(a, b [gcmBlockSize]byte) [gcmBlockSize]byte {
+	x := polyvalElement(a)
+	y := polyvalElement(b)
+	y = gcmDouble(&y)
+	return polyvalBlock(gcmMul(&x, &y))
+}
+
+// polyvalMulH returns dot(a, h), where g's product table was built from
+// polyvalSubkey(h).
+func (g *gcm) polyvalMulH(a [gcmBlockSize]byte) [gcmBlockSize]byte {
+	x := polyvalElement(a)
+	g.mul(&x)
+	return polyvalBlock(x)
+}
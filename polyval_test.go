@@ -0,0 +1,51 @@
+package uncheckedgcm
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeBlock(t *testing.T, s string) (block [gcmBlockSize]byte) {
+	copy(block[:], decodeHex(t, s))
+	return
+}
+
+// polyval computes POLYVAL(h, blocks...) with mulH multiplying by h.
+func polyval(blocks [][gcmBlockSize]byte, mulH func([gcmBlockSize]byte) [gcmBlockSize]byte) [gcmBlockSize]byte {
+	var s [gcmBlockSize]byte
+	for _, block := range blocks {
+		for i := range s {
+			s[i] ^= block[i]
+		}
+		s = mulH(s)
+	}
+	return s
+}
+
+func TestPOLYVAL(t *testing.T) {
+	// RFC 8452, Appendix A.
+	h := decodeBlock(t, "25629347589242761d31f826ba4b757b")
+	blocks := [][gcmBlockSize]byte{
+		decodeBlock(t, "4f4f95668c83dfb6401762bb2d01a262"),
+		decodeBlock(t, "d1a24ddd2721d006bbe45f20d3c9f362"),
+	}
+	want := "f7a3b47b846119fae5b7866cf5e5b77e"
+
+	generic := polyval(blocks, func(x [gcmBlockSize]byte) [gcmBlockSize]byte {
+		return polyvalMul(x, h)
+	})
+	assert.Equal(t, want, hex.EncodeToString(generic[:]))
+
+	g := &gcm{}
+	g.setSubkey(polyvalSubkey(h))
+	table := polyval(blocks, g.polyvalMulH)
+	assert.Equal(t, want, hex.EncodeToString(table[:]))
+}
+
+func TestPOLYVALMulCommutes(t *testing.T) {
+	a := decodeBlock(t, "4f4f95668c83dfb6401762bb2d01a262")
+	b := decodeBlock(t, "d1a24ddd2721d006bbe45f20d3c9f362")
+	assert.Equal(t, polyvalMul(a, b), polyvalMul(b, a))
+}
@@ -0,0 +1,62 @@
+package uncheckedgcm
+
+import "crypto/cipher"
+
+// NoncePrefix holds the GHASH of a nonce prefix shared by many messages, so
+// that deriving J0 for each message only hashes the rest of its nonce.
+type NoncePrefix struct {
+	base      *gcm
+	ghash     gcmFieldElement
+	prefixLen int
+}
+
+// NewNoncePrefix hashes prefix under cipher's hash subkey, for use with
+// nonces that begin with it. It panics if the length of prefix is not a
+// multiple of 16 bytes.
+func NewNoncePrefix(cipher cipher.Block, prefix []byte) *NoncePrefix {
+	if len(prefix)%gcmBlockSize != 0 {
+		panic("gcm: nonce prefix must be a whole number of blocks")
+	}
+
+	p := &NoncePrefix{
+		base:      newGCM(cipher),
+		prefixLen: len(prefix),
+	}
+	p.base.updateBlocks(&p.ghash, prefix)
+
+	return p
+}
+
+// start begins a message on a fresh copy of the base gcm whose nonce is the
+// prefix followed by rest.
+func (p *NoncePrefix) start(rest, additionalData []byte) *gcm {
+	// A non-empty prefix already makes the nonce valid.
+	if p.prefixLen == 0 {
+		if err := Validate(rest, additionalData); err != nil {
+			panic(err)
+		}
+	}
+
+	g := p.base.fork()
+	g.startAD(additionalData)
+	g.deriveCounterFromState(p.ghash, p.prefixLen, rest)
+	g.startCounter()
+
+	return g
+}
+
+// NewEncrypter returns an encrypter for the nonce prefix||rest.
+func (p *NoncePrefix) NewEncrypter(rest, additionalData []byte) *Encrypter {
+	return &Encrypter{
+		gcm:              p.start(rest, additionalData),
+		additionalDataNb: uint64(len(additionalData)),
+	}
+}
+
+// NewDecrypter returns a decrypter for the nonce prefix||rest.
+func (p *NoncePrefix) NewDecrypter(rest, additionalData []byte) *Decrypter {
+	return &Decrypter{
+		gcm:              p.start(rest, additionalData),
+		additionalDataNb: uint64(len(additionalData)),
+	}
+}
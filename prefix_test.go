@@ -0,0 +1,45 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoncePrefix(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	longNonce := make([]byte, 80)
+	for i := range longNonce {
+		longNonce[i] = byte(i * 3)
+	}
+
+	for _, prefixLen := range []int{0, 16, 32, 64} {
+		p := NewNoncePrefix(block, longNonce[:prefixLen])
+
+		for _, restLen := range []int{1, 12, 15, 16} {
+			full := longNonce[:prefixLen+restLen]
+			rest := full[prefixLen:]
+
+			want := newGCMEncrypter(block, full, []byte("ad"))
+			e := p.NewEncrypter(rest, []byte("ad"))
+			assert.Equal(t, want.InitialCounter(), e.InitialCounter())
+			assert.Equal(t, want.tagMask, e.tagMask)
+
+			ciphertext := e.Encrypt(nil, decryptedPacket)
+			assert.Equal(t, want.Encrypt(nil, decryptedPacket), ciphertext)
+			tag := e.Tag()
+			assert.Equal(t, want.Tag(), tag)
+
+			d := p.NewDecrypter(rest, []byte("ad"))
+			_, err := d.Decrypt(nil, ciphertext)
+			assert.Nil(t, err)
+			assert.Nil(t, d.VerifyArray(tag))
+		}
+	}
+
+	assert.Panics(t, func() { NewNoncePrefix(block, longNonce[:12]) })
+	assert.Panics(t, func() { NewNoncePrefix(block, nil).NewEncrypter(nil, nil) })
+}
@@ -0,0 +1,27 @@
+package uncheckedgcm
+
+// WasDataProcessed reports whether Encrypt has been given any plaintext.
+// Tag with no plaintext is valid and authenticates only the additional
+// data, so callers that expect to have encrypted something can check this
+// before finalizing. Empty Encrypt calls do not count.
+func (g *Encrypter) WasDataProcessed() bool {
+	return g.plaintextNb > 0
+}
+
+// WasDataProcessed reports whether Decrypt has been given any ciphertext.
+func (g *Decrypter) WasDataProcessed() bool {
+	return g.ciphertextNb > 0
+}
+
+// Stats returns the number of additional data bytes authenticated and
+// plaintext bytes encrypted so far. The additional data count includes the
+// constructor's additional data and anything added since.
+func (g *Encrypter) Stats() (additionalDataBytes, dataBytes uint64) {
+	return g.additionalDataNb, g.plaintextNb
+}
+
+// Stats returns the number of additional data bytes authenticated and
+// ciphertext bytes decrypted so far.
+func (g *Decrypter) Stats() (additionalDataBytes, dataBytes uint64) {
+	return g.additionalDataNb, g.ciphertextNb
+}
@@ -0,0 +1,52 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWasDataProcessed(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, []byte("ad"))
+	assert.False(t, e.WasDataProcessed())
+	e.Encrypt(nil, nil)
+	assert.False(t, e.WasDataProcessed())
+	e.Encrypt(nil, decryptedPacket[:1])
+	assert.True(t, e.WasDataProcessed())
+	e.Tag()
+	assert.True(t, e.WasDataProcessed())
+
+	d := newGCMDecrypter(block, nonce, []byte("ad"))
+	assert.False(t, d.WasDataProcessed())
+	_, err = d.Decrypt(nil, encryptedPacket)
+	assert.Nil(t, err)
+	assert.True(t, d.WasDataProcessed())
+}
+
+func TestStats(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, []byte("header"))
+	e.AddAdditionalData([]byte("more"))
+	for i := 0; i < len(decryptedPacket); i += 3 {
+		e.Encrypt(nil, decryptedPacket[i:min(i+3, len(decryptedPacket))])
+	}
+	ad, data := e.Stats()
+	assert.Equal(t, uint64(10), ad)
+	assert.Equal(t, uint64(len(decryptedPacket)), data)
+
+	d := newLazyGCMDecrypter(block, nonce, []byte("header"))
+	for i := 0; i < len(encryptedPacket); i += 7 {
+		_, err := d.Decrypt(nil, encryptedPacket[i:min(i+7, len(encryptedPacket))])
+		assert.Nil(t, err)
+	}
+	d.AddTrailingAD([]byte("trailer"))
+	ad, data = d.Stats()
+	assert.Equal(t, uint64(13), ad)
+	assert.Equal(t, uint64(len(encryptedPacket)), data)
+}
@@ -0,0 +1,25 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"fmt"
+	"io"
+)
+
+// newGCMEncrypterRandomNonce reads a 16-byte nonce from random, or from
+// crypto/rand.Reader if random is nil, and returns it with an encrypter for
+// it. The caller must send the nonce along with the ciphertext. An error is
+// returned if random fails.
+func newGCMEncrypterRandomNonce(cipher cipher.Block, random io.Reader, additionalData []byte) (*Encrypter, []byte, error) {
+	if random == nil {
+		random = rand.Reader
+	}
+
+	nonce := make([]byte, gcmNonceSize)
+	if _, err := io.ReadFull(random, nonce); err != nil {
+		return nil, nil, fmt.Errorf("gcm: reading nonce: %w", err)
+	}
+
+	return newGCMEncrypter(cipher, nonce, additionalData), nonce, nil
+}
@@ -0,0 +1,44 @@
+package uncheckedgcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRandomNonce(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e, n, err := newGCMEncrypterRandomNonce(block, bytes.NewReader(nonce), nil)
+	assert.Nil(t, err)
+	assert.Equal(t, nonce, n)
+
+	want := newGCMEncrypter(block, nonce, nil)
+	assert.Equal(t, want.Encrypt(nil, decryptedPacket), e.Encrypt(nil, decryptedPacket))
+	assert.Equal(t, want.Tag(), e.Tag())
+
+	_, n1, err := newGCMEncrypterRandomNonce(block, nil, nil)
+	assert.Nil(t, err)
+	_, n2, err := newGCMEncrypterRandomNonce(block, nil, nil)
+	assert.Nil(t, err)
+	assert.Len(t, n1, gcmNonceSize)
+	assert.NotEqual(t, n1, n2)
+}
+
+func TestRandomNonceReaderFails(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	errEntropy := errors.New("entropy source unavailable")
+	_, _, err = newGCMEncrypterRandomNonce(block, iotest.ErrReader(errEntropy), nil)
+	assert.ErrorIs(t, err, errEntropy)
+
+	_, _, err = newGCMEncrypterRandomNonce(block, bytes.NewReader(nonce[:8]), nil)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
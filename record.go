@@ -0,0 +1,83 @@
+package uncheckedgcm
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const recordLengthSize = 4
+
+// RecordError reports which record in a stream could not be read or
+// verified.
+type RecordError struct {
+	Index int
+	Err   error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("gcm: record %d: %v", e.Index, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+// RecordReader reads independent GCM records stored back to back. Each
+// record is laid out as nonce||length||ciphertext||tag, where length is the
+// ciphertext length as a 4-byte big-endian integer. Every record is
+// decrypted with a fresh decrypter and only returned once its tag verifies.
+type RecordReader struct {
+	r      io.Reader
+	cipher cipher.Block
+	index  int
+	buf    bytes.Buffer
+}
+
+func newRecordReader(r io.Reader, cipher cipher.Block) *RecordReader {
+	return &RecordReader{r: r, cipher: cipher}
+}
+
+// Next returns the plaintext of the next record. It returns io.EOF once the
+// stream ends cleanly on a record boundary. Any other failure is a
+// *RecordError; a record whose tag does not verify wraps ErrOpen and the
+// reader moves on to the following record.
+func (rr *RecordReader) Next() ([]byte, error) {
+	index := rr.index
+
+	var header [gcmNonceSize + recordLengthSize]byte
+	if _, err := io.ReadFull(rr.r, header[:]); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, &RecordError{index, err}
+	}
+	n := binary.BigEndian.Uint32(header[gcmNonceSize:])
+
+	rr.buf.Reset()
+	if _, err := io.CopyN(&rr.buf, rr.r, int64(n)+gcmTagSize); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, &RecordError{index, err}
+	}
+	rr.index++
+
+	record := rr.buf.Bytes()
+	ciphertext, tag := record[:n], record[n:]
+
+	g := newGCMDecrypter(rr.cipher, header[:gcmNonceSize], nil)
+
+	plaintext, err := g.Decrypt(nil, ciphertext)
+	if err != nil {
+		return nil, &RecordError{index, err}
+	}
+	if err := g.Verify(tag); err != nil {
+		return nil, &RecordError{index, err}
+	}
+
+	return plaintext, nil
+}
@@ -0,0 +1,88 @@
+package uncheckedgcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func appendRecord(dst []byte, block cipher.Block, nonce, plaintext []byte) []byte {
+	dst = append(dst, nonce...)
+	dst = binary.BigEndian.AppendUint32(dst, uint32(len(plaintext)))
+
+	g := newGCMEncrypter(block, nonce, nil)
+	dst = g.Encrypt(dst, plaintext)
+
+	tag := g.Tag()
+	return append(dst, tag[:]...)
+}
+
+func TestRecordReader(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintexts := [][]byte{[]byte("first"), {}, []byte("third record is a little longer")}
+
+	var stream []byte
+	for i, plaintext := range plaintexts {
+		n := append([]byte{}, nonce...)
+		n[0] += byte(i)
+		stream = appendRecord(stream, block, n, plaintext)
+	}
+
+	rr := newRecordReader(bytes.NewReader(stream), block)
+	for _, want := range plaintexts {
+		got, err := rr.Next()
+		assert.Nil(t, err)
+		assert.True(t, bytes.Equal(want, got))
+	}
+
+	_, err = rr.Next()
+	assert.ErrorIs(t, err, io.EOF)
+}
+
+func TestRecordReaderCorrupted(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	stream := appendRecord(nil, block, nonce, []byte("first"))
+	middle := len(stream)
+	stream = appendRecord(stream, block, nonce, []byte("second"))
+	stream[middle+gcmNonceSize+recordLengthSize] ^= 1
+	stream = appendRecord(stream, block, nonce, []byte("third"))
+
+	rr := newRecordReader(bytes.NewReader(stream), block)
+
+	plaintext, err := rr.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("first"), plaintext)
+
+	plaintext, err = rr.Next()
+	assert.Nil(t, plaintext)
+	assert.ErrorIs(t, err, ErrOpen)
+
+	var recordErr *RecordError
+	assert.ErrorAs(t, err, &recordErr)
+	assert.Equal(t, 1, recordErr.Index)
+
+	plaintext, err = rr.Next()
+	assert.Nil(t, err)
+	assert.Equal(t, []byte("third"), plaintext)
+}
+
+func TestRecordReaderTruncated(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	stream := appendRecord(nil, block, nonce, []byte("first"))
+
+	for _, n := range []int{5, gcmNonceSize + recordLengthSize + 2, len(stream) - 1} {
+		_, err := newRecordReader(bytes.NewReader(stream[:n]), block).Next()
+		assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	}
+}
@@ -0,0 +1,188 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"errors"
+	"fmt"
+)
+
+// ErrCipherFailure is wrapped by the errors the recovering constructors
+// return when the block cipher panics.
+var ErrCipherFailure = errors.New("gcm: block cipher failed")
+
+// recoveringBlock turns a panic in the wrapped block into a recorded
+// error. After a failure it stops calling the block and writes zeros, since
+// its output is about to be discarded.
+type recoveringBlock struct {
+	cipher.Block
+	err error
+}
+
+func (b *recoveringBlock) Encrypt(dst, src []byte) {
+	if b.err != nil {
+		clear(dst[:b.BlockSize()])
+		return
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			b.err = fmt.Errorf("%w: %v", ErrCipherFailure, v)
+			clear(dst[:b.BlockSize()])
+		}
+	}()
+	b.Block.Encrypt(dst, src)
+}
+
+// recoveringBulkBlock is recoveringBlock for a BulkBlock, so the bulk path
+// is kept.
+type recoveringBulkBlock struct {
+	*recoveringBlock
+	bulk BulkBlock
+}
+
+func (b recoveringBulkBlock) EncryptBlocks(dst, src []byte) {
+	if b.err != nil {
+		clear(dst)
+		return
+	}
+
+	defer func() {
+		if v := recover(); v != nil {
+			b.err = fmt.Errorf("%w: %v", ErrCipherFailure, v)
+			clear(dst)
+		}
+	}()
+	b.bulk.EncryptBlocks(dst, src)
+}
+
+// newRecoveringGCM wraps block so its panics are recorded rather than
+// propagated and builds a gcm on it. The hash subkey is computed here, so a
+// block that fails straight away is reported as an error.
+func newRecoveringGCM(block cipher.Block) (*gcm, *recoveringBlock, error) {
+	r := &recoveringBlock{Block: block}
+
+	var wrapped cipher.Block = r
+	if bulk, ok := block.(BulkBlock); ok {
+		wrapped = recoveringBulkBlock{r, bulk}
+	}
+
+	g := newGCM(wrapped)
+	if r.err != nil {
+		return nil, nil, r.err
+	}
+	return g, r, nil
+}
+
+// RecoveringEncrypter is an encrypter for block ciphers that may panic,
+// such as a remote key service that panics on timeout. A panic in the block
+// cipher is returned as an error wrapping ErrCipherFailure instead of
+// unwinding the caller. The message is then abandoned: its state is
+// discarded and every later call returns the same error. Panics caused by
+// misuse, such as overlapping buffers, still propagate.
+//
+// Recovering costs a deferred call per block, so it is opt-in.
+type RecoveringEncrypter struct {
+	g     *Encrypter
+	block *recoveringBlock
+}
+
+// NewRecoveringEncrypter returns a RecoveringEncrypter for the given nonce
+// and additional data. If block panics while the hash subkey or counter is
+// being derived, the error is returned.
+func NewRecoveringEncrypter(block cipher.Block, nonce, additionalData []byte) (*RecoveringEncrypter, error) {
+	g, r, err := newRecoveringGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &RecoveringEncrypter{g: g.newEncrypter(nonce, additionalData), block: r}
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// check discards the message if the block cipher has failed.
+func (e *RecoveringEncrypter) check() error {
+	if e.block.err != nil && !e.g.discarded {
+		e.g.Discard()
+	}
+	return e.block.err
+}
+
+// Encrypt is the encrypter's Encrypt. If the block cipher fails, the output
+// is zeroed and an error is returned in place of the ciphertext.
+func (e *RecoveringEncrypter) Encrypt(dst, plaintext []byte) ([]byte, error) {
+	if err := e.check(); err != nil {
+		return nil, err
+	}
+
+	ret := e.g.Encrypt(dst, plaintext)
+	if err := e.check(); err != nil {
+		clear(ret[len(dst):])
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Tag is the encrypter's Tag, or the block cipher's error if it failed.
+func (e *RecoveringEncrypter) Tag() ([gcmTagSize]byte, error) {
+	if err := e.check(); err != nil {
+		return [gcmTagSize]byte{}, err
+	}
+	return e.g.Tag(), nil
+}
+
+// RecoveringDecrypter is the decrypting counterpart of RecoveringEncrypter.
+type RecoveringDecrypter struct {
+	g     *Decrypter
+	block *recoveringBlock
+}
+
+// NewRecoveringDecrypter is the decrypting counterpart of
+// NewRecoveringEncrypter.
+func NewRecoveringDecrypter(block cipher.Block, nonce, additionalData []byte) (*RecoveringDecrypter, error) {
+	g, r, err := newRecoveringGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &RecoveringDecrypter{g: g.newDecrypter(nonce, additionalData), block: r}
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *RecoveringDecrypter) check() error {
+	if d.block.err != nil && !d.g.discarded {
+		d.g.Discard()
+	}
+	return d.block.err
+}
+
+// Decrypt is the decrypter's Decrypt. If the block cipher fails, the output
+// is zeroed and the block cipher's error is returned.
+func (d *RecoveringDecrypter) Decrypt(dst, ciphertext []byte) ([]byte, error) {
+	if err := d.check(); err != nil {
+		return nil, err
+	}
+
+	ret, err := d.g.Decrypt(dst, ciphertext)
+	if cerr := d.check(); cerr != nil {
+		if err == nil {
+			clear(ret[len(dst):])
+		}
+		return nil, cerr
+	}
+	return ret, err
+}
+
+// Verify is the decrypter's Verify, or the block cipher's error if it
+// failed.
+func (d *RecoveringDecrypter) Verify(tag []byte) error {
+	if err := d.check(); err != nil {
+		return err
+	}
+	return d.g.Verify(tag)
+}
@@ -0,0 +1,80 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveringEncrypter(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// The hash subkey and tag mask use the first two calls, so the third
+	// is the first keystream block.
+	e, err := NewRecoveringEncrypter(&panickingBlock{Block: aesBlock, limit: 2}, nonce, nil)
+	assert.Nil(t, err)
+
+	out, err := e.Encrypt(nil, decryptedPacket)
+	assert.ErrorIs(t, err, ErrCipherFailure)
+	assert.ErrorContains(t, err, "block failure")
+	assert.Nil(t, out)
+
+	_, err = e.Encrypt(nil, decryptedPacket)
+	assert.ErrorIs(t, err, ErrCipherFailure)
+	_, err = e.Tag()
+	assert.ErrorIs(t, err, ErrCipherFailure)
+	assert.True(t, e.g.discarded)
+
+	// A healthy block behaves like the plain encrypter.
+	e, err = NewRecoveringEncrypter(aesBlock, nonce, nil)
+	assert.Nil(t, err)
+	ciphertext, err := e.Encrypt(nil, decryptedPacket)
+	assert.Nil(t, err)
+	assert.Equal(t, encryptedPacket, ciphertext)
+
+	// Misuse still panics.
+	buf := make([]byte, 64)
+	assert.Panics(t, func() { e.Encrypt(buf[1:1], buf[:20]) })
+
+	_, err = NewRecoveringEncrypter(&panickingBlock{Block: aesBlock}, nonce, nil)
+	assert.ErrorIs(t, err, ErrCipherFailure)
+}
+
+func TestRecoveringDecrypter(t *testing.T) {
+	aesBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	d, err := NewRecoveringDecrypter(&panickingBlock{Block: aesBlock, limit: 3}, nonce, nil)
+	assert.Nil(t, err)
+
+	// The first block of keystream succeeds and the second fails.
+	plaintext, err := d.Decrypt(nil, encryptedPacket[:16])
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket[:16], plaintext)
+
+	_, err = d.Decrypt(nil, encryptedPacket[16:])
+	assert.ErrorIs(t, err, ErrCipherFailure)
+	assert.ErrorIs(t, d.Verify(tag[:]), ErrCipherFailure)
+
+	// The bulk path recovers too, even on the pipelined path.
+	bulk := &panickingBulkBlock{bulkBlock: bulkBlock{Block: aesBlock}, limit: 1}
+	e, err := NewRecoveringEncrypter(bulk, nonce, nil)
+	assert.Nil(t, err)
+	_, err = e.Encrypt(nil, make([]byte, pipelineThreshold))
+	assert.ErrorIs(t, err, ErrCipherFailure)
+}
+
+type panickingBulkBlock struct {
+	bulkBlock
+	limit, calls int
+}
+
+func (b *panickingBulkBlock) EncryptBlocks(dst, src []byte) {
+	b.calls++
+	if b.calls > b.limit {
+		panic("bulk failure")
+	}
+	b.bulkBlock.EncryptBlocks(dst, src)
+}
@@ -0,0 +1,55 @@
+package uncheckedgcm
+
+import "crypto/subtle"
+
+// Reencrypt moves ciphertext from the message being read by old to the
+// message being written by g, appending the new ciphertext to dst. Instead
+// of decrypting, it XORs ciphertext with the difference of the two
+// keystreams, so the plaintext is never held in memory. old authenticates
+// ciphertext as Decrypt would, so old.Verify checks the original tag, and g
+// authenticates the new ciphertext as Encrypt would.
+//
+// Neither side may be in MAC-then-encrypt mode, since the plaintext is
+// never available to hash.
+//
+// This suits key rotation by a proxy that holds both keys but should not
+// see the data.
+func (g *Encrypter) Reencrypt(dst []byte, old *Decrypter, ciphertext []byte) []byte {
+	g.mustBeLive()
+	old.mustBeLive()
+	if g.finalized || old.finalized {
+		panic(ErrFinalized)
+	}
+	if g.macPlaintext || old.macPlaintext {
+		panic("gcm: Reencrypt needs both sides to authenticate ciphertext")
+	}
+
+	ret, out := sliceForAppend(dst, len(ciphertext))
+	if inexactOverlap(out, ciphertext) {
+		panic("gcm: invalid buffer overlap")
+	}
+	if err := old.checkKeystream(len(ciphertext)); err != nil {
+		panic(err)
+	}
+	if err := g.checkKeystream(len(ciphertext)); err != nil {
+		panic(err)
+	}
+
+	old.endAD()
+	old.updateStream(ciphertext)
+	old.ciphertextNb += uint64(len(ciphertext))
+
+	// Build the keystream difference in out before touching the
+	// ciphertext, so out never holds plaintext.
+	delta := make([]byte, len(ciphertext))
+	old.counterCrypt(delta, delta, &old.counter)
+	g.counterCrypt(delta, delta, &g.counter)
+	subtle.XORBytes(out, ciphertext, delta)
+	clear(delta)
+
+	g.endAD()
+	g.updateStream(out)
+	g.plaintextNb += uint64(len(ciphertext))
+
+	return ret
+}
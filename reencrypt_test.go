@@ -0,0 +1,54 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReencrypt(t *testing.T) {
+	oldBlock, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+	newKey := append([]byte(nil), key...)
+	newKey[0] ^= 0xff
+	newBlock, err := aes.NewCipher(newKey)
+	assert.Nil(t, err)
+
+	newNonce := append([]byte(nil), nonce...)
+	newNonce[15] ^= 1
+
+	plaintext := make([]byte, 77)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	e := newGCMEncrypter(oldBlock, nonce, []byte("old"))
+	oldCiphertext := e.Encrypt(nil, plaintext)
+	oldTag := e.Tag()
+
+	// Decrypt then re-encrypt, the straightforward way.
+	want := newGCMEncrypter(newBlock, newNonce, []byte("new"))
+	wantCiphertext := want.Encrypt(nil, plaintext)
+	wantTag := want.Tag()
+
+	for _, inPlace := range []bool{false, true} {
+		old := newGCMDecrypter(oldBlock, nonce, []byte("old"))
+		g := newGCMEncrypter(newBlock, newNonce, []byte("new"))
+
+		buf := append([]byte(nil), oldCiphertext...)
+		var got []byte
+		for _, chunk := range [][2]int{{0, 5}, {5, 40}, {40, 77}} {
+			src := buf[chunk[0]:chunk[1]]
+			if inPlace {
+				got = g.Reencrypt(buf[:chunk[0]], old, src)
+			} else {
+				got = g.Reencrypt(got, old, src)
+			}
+		}
+
+		assert.Equal(t, wantCiphertext, got)
+		assert.Equal(t, wantTag, g.Tag())
+		assert.Nil(t, old.VerifyArray(oldTag))
+	}
+}
@@ -0,0 +1,48 @@
+package uncheckedgcm
+
+import "encoding/binary"
+
+// ReNonce switches the keystream to one derived from nonce while keeping the
+// running GHASH and length counters, so a single tag covers data encrypted
+// under several nonces. The tag mask is recomputed from the new nonce, and
+// any keystream precomputed for the old nonce is discarded.
+//
+// This is not GCM and is dangerous: the resulting tag does not bind which
+// data was encrypted under which nonce, and reusing any nonce under the same
+// key reveals the XOR of plaintexts as usual. Only use it to interoperate
+// with a wire format that requires it.
+func (g *gcm) ReNonce(nonce []byte) {
+	if err := Validate(nonce, nil); err != nil {
+		panic(err)
+	}
+
+	clear(g.extraMask)
+	g.extraMask = g.extraMask[:0]
+
+	g.setNonce(nonce)
+}
+
+// ResetCounter rewinds the keystream to the start of the message, keeping
+// the running GHASH and length counters. Data encrypted afterwards uses the
+// identical keystream to data encrypted from the start, so it reveals the
+// XOR of the old and new plaintexts at the same positions; it is only meant
+// for retransmitting the same plaintext.
+func (g *gcm) ResetCounter() {
+	clear(g.extraMask)
+	g.extraMask = g.extraMask[:0]
+
+	g.counter = g.initialCounter
+}
+
+// SetInitialCounterValue sets the low 32 bits of the first keystream
+// counter block to v, for peers that do not start at J0+1. The tag mask is
+// still E(J0). It must be called before any data is processed; v = 2 with a
+// 12-byte nonce is standard GCM.
+func (g *gcm) SetInitialCounterValue(v uint32) {
+	if g.counter != g.initialCounter || len(g.extraMask) > 0 {
+		panic("gcm: initial counter value set after data was processed")
+	}
+
+	binary.BigEndian.PutUint32(g.counter[gcmBlockSize-4:], v)
+	g.initialCounter = g.counter
+}
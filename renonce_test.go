@@ -0,0 +1,137 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReNonce(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	nonce2 := make([]byte, gcmNonceSize)
+	copy(nonce2, nonce)
+	nonce2[0] ^= 0xff
+
+	plaintext := make([]byte, 21)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	first := e.Encrypt(nil, plaintext)
+
+	ghash, tail, plaintextNb := e.ghash, e.ghashTail, e.plaintextNb
+	e.ReNonce(nonce2)
+	assert.Equal(t, ghash, e.ghash)
+	assert.Equal(t, tail, e.ghashTail)
+	assert.Equal(t, plaintextNb, e.plaintextNb)
+
+	// The keystream restarts from the new nonce's first counter block.
+	second := e.Encrypt(nil, plaintext)
+	fresh := newGCMEncrypter(block, nonce2, nil)
+	assert.Equal(t, fresh.Encrypt(nil, plaintext), second)
+	assert.NotEqual(t, first, second)
+	assert.Equal(t, fresh.tagMask, e.tagMask)
+	tag := e.Tag()
+
+	// The tag covers both parts as one message.
+	ref := newGCMEncrypter(block, nonce2, nil)
+	ref.updateStream(first)
+	ref.updateStream(second)
+	ref.plaintextNb = uint64(len(first) + len(second))
+	assert.Equal(t, ref.Tag(), tag)
+
+	d := newGCMDecrypter(block, nonce, nil)
+	got, err := d.Decrypt(nil, first)
+	assert.Nil(t, err)
+	d.ReNonce(nonce2)
+	got, err = d.Decrypt(got, second)
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 2*len(plaintext)), got)
+	assert.Nil(t, d.VerifyArray(tag))
+}
+
+func TestResetCounter(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 37)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	e := newGCMEncrypter(block, nonce, nil)
+	first := e.Encrypt(nil, plaintext[:5])
+	first = e.Encrypt(first, plaintext[5:])
+
+	ghash, plaintextNb := e.ghash, e.plaintextNb
+	e.ResetCounter()
+	assert.Equal(t, ghash, e.ghash)
+	assert.Equal(t, plaintextNb, e.plaintextNb)
+	assert.Equal(t, e.InitialCounter(), e.FinalCounter())
+
+	// Split differently so leftover keystream from before the reset would
+	// show up as a mismatch.
+	second := e.Encrypt(nil, plaintext[:20])
+	second = e.Encrypt(second, plaintext[20:])
+	assert.Equal(t, first, second)
+	tag := e.Tag()
+
+	// The GHASH covers both copies.
+	ref := newGCMEncrypter(block, nonce, nil)
+	ref.updateStream(first)
+	ref.updateStream(second)
+	ref.plaintextNb = uint64(len(first) + len(second))
+	assert.Equal(t, ref.Tag(), tag)
+}
+
+func TestSetInitialCounterValue(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	n := nonce[:gcmStandardNonceSize]
+	plaintext := make([]byte, 50)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	for _, v := range []uint32{0, 1, 2, 7, 0xffffffff} {
+		e := newGCMEncrypter(block, n, nil)
+		tagMask := e.tagMask
+		e.SetInitialCounterValue(v)
+		assert.Equal(t, tagMask, e.tagMask)
+
+		// The keystream is plain CTR from nonce||v, wrapping only the low
+		// 32 bits.
+		var iv [gcmBlockSize]byte
+		copy(iv[:], n)
+		binary.BigEndian.PutUint32(iv[gcmBlockSize-4:], v)
+		want := make([]byte, len(plaintext))
+		for i := 0; i < len(plaintext); i += gcmBlockSize {
+			var mask [gcmBlockSize]byte
+			block.Encrypt(mask[:], iv[:])
+			subtle.XORBytes(want[i:], plaintext[i:], mask[:])
+			gcmInc32(&iv)
+		}
+
+		got := e.Encrypt(nil, plaintext)
+		assert.Equal(t, want, got, "v = %d", v)
+
+		d := newGCMDecrypter(block, n, nil)
+		d.SetInitialCounterValue(v)
+		opened, err := d.Decrypt(nil, got)
+		assert.Nil(t, err)
+		assert.Equal(t, plaintext, opened)
+		assert.Nil(t, d.VerifyArray(e.Tag()))
+	}
+
+	// 2 is the standard starting value.
+	std := newGCMEncrypter(block, n, nil)
+	e := newGCMEncrypter(block, n, nil)
+	e.SetInitialCounterValue(2)
+	assert.Equal(t, std.Encrypt(nil, plaintext), e.Encrypt(nil, plaintext))
+
+	assert.Panics(t, func() { e.SetInitialCounterValue(1) })
+}
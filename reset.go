@@ -0,0 +1,51 @@
+package uncheckedgcm
+
+// restart wipes the state of the message in progress and starts a new one
+// under nonce and additionalData. The product table, field and settings such
+// as the counter batch size, length block, mode and checkpoints are kept, so
+// a new message costs only the tag mask and counter derivation.
+func (g *gcm) restart(nonce, additionalData []byte) {
+	g.mustBeLive()
+	if err := Validate(nonce, additionalData); err != nil {
+		panic(err)
+	}
+
+	clear(g.extraMask)
+	clear(g.lazyAD)
+	*g = gcm{
+		cipher:          g.cipher,
+		counterBatch:    g.counterBatch,
+		productTable:    g.productTable,
+		field:           g.field,
+		lengthBlock:     g.lengthBlock,
+		deferAD:         g.deferAD,
+		constantTimePad: g.constantTimePad,
+		macPlaintext:    g.macPlaintext,
+		interleaved:     g.interleaved,
+		checkpointEvery: g.checkpointEvery,
+		onCheckpoint:    g.onCheckpoint,
+	}
+	g.start(nonce, additionalData)
+}
+
+// Reset abandons the message in progress and starts a new one under nonce
+// and additionalData, reusing the hash subkey's product table. The caller
+// must not reuse a nonce under the same key.
+func (g *Encrypter) Reset(nonce, additionalData []byte) {
+	g.restart(nonce, additionalData)
+	g.plaintextNb = 0
+	g.additionalDataNb = uint64(len(additionalData))
+}
+
+// Reset abandons the message in progress and starts a new one under nonce
+// and additionalData, reusing the hash subkey's product table. It suits
+// retrying a message whose additional data has changed. The observer is
+// kept; the expected tag and any expected or bound length are cleared.
+func (g *Decrypter) Reset(nonce, additionalData []byte) {
+	g.restart(nonce, additionalData)
+	*g = Decrypter{
+		gcm:              g.gcm,
+		additionalDataNb: uint64(len(additionalData)),
+		observer:         g.observer,
+	}
+}
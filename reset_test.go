@@ -0,0 +1,62 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecrypterReset(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, []byte("new ad"))
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	old := newGCMEncrypter(block, nonce, []byte("old ad"))
+	old.Encrypt(nil, decryptedPacket)
+	oldTag := old.Tag()
+
+	d := newGCMDecrypter(block, nonce, []byte("old ad"))
+	d.SetExpectedTag(tag[:])
+	_, err = d.Decrypt(nil, ciphertext[:7])
+	assert.Nil(t, err)
+
+	// Retry the whole message with the new additional data.
+	d.Reset(nonce, []byte("new ad"))
+	plaintext, err := d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+	assert.Equal(t, errNoExpectedTag, d.VerifyExpected())
+	assert.Nil(t, d.VerifyArray(tag))
+
+	d.Reset(nonce, []byte("new ad"))
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrOpen, d.VerifyArray(oldTag))
+}
+
+func TestEncrypterReset(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newLazyGCMEncrypter(block, nonce, []byte("first"))
+	e.Encrypt(nil, make([]byte, 40))
+	e.Tag()
+
+	e.Reset(nonce, []byte("second"))
+	assert.True(t, e.deferAD)
+
+	reference := newLazyGCMEncrypter(block, nonce, []byte("second"))
+	assert.Equal(t, reference.Encrypt(nil, decryptedPacket), e.Encrypt(nil, decryptedPacket))
+	assert.Equal(t, reference.Tag(), e.Tag())
+	ad, data := e.Stats()
+	assert.Equal(t, uint64(len("second")), ad)
+	assert.Equal(t, uint64(len(decryptedPacket)), data)
+
+	d := newGCMDecrypter(block, nonce, nil)
+	d.Discard()
+	assert.PanicsWithValue(t, ErrDiscarded, func() { d.Reset(nonce, nil) })
+}
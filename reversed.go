@@ -0,0 +1,89 @@
+package uncheckedgcm
+
+import "encoding/binary"
+
+// gcmOne is the multiplicative identity in GF(2^128).
+var gcmOne = gcmFieldElement{1 << 63, 0}
+
+// reversedAD accumulates additional data that is supplied last block first.
+//
+// GHASH over blocks A1..An starting from state G is
+//
+//	G·H^n + A1·H^n + A2·H^(n-1) + ... + An·H
+//
+// so feeding An first, each block is multiplied by the next power of H and
+// the state the blocks started from is multiplied by the final power.
+type reversedAD struct {
+	base  gcmFieldElement
+	sum   gcmFieldElement
+	power gcmFieldElement
+}
+
+func (g *gcm) addADReversed(block []byte) {
+	if len(block) == 0 {
+		return
+	}
+
+	if len(block) > gcmBlockSize {
+		panic("gcm: reversed additional data block too large")
+	}
+
+	r := &g.reversed
+	if r.power == (gcmFieldElement{}) {
+		// The blocks are folded in as whole blocks, so whatever came
+		// before them must end on a block boundary.
+		if g.ghashTailNb != 0 {
+			panic("gcm: reversed additional data must start on a block boundary")
+		}
+		r.base = g.ghash
+		r.power = gcmOne
+	} else if len(block) != gcmBlockSize {
+		panic("gcm: only the first reversed additional data block may be partial")
+	}
+
+	var padded [gcmBlockSize]byte
+	copy(padded[:], block)
+
+	x := gcmFieldElement{
+		binary.BigEndian.Uint64(padded[:8]),
+		binary.BigEndian.Uint64(padded[8:]),
+	}
+
+	g.mul(&r.power)
+	x = g.field.mul(&x, &r.power)
+	r.sum = gcmAdd(&r.sum, &x)
+
+	g.ghash = g.field.mul(&r.base, &r.power)
+	g.ghash = gcmAdd(&g.ghash, &r.sum)
+	g.ghashBlocks++
+}
+
+// AddADReversed authenticates additional data supplied one block at a time
+// from the last block to the first. The first call takes the final, possibly
+// partial, block; every later call must be a full block. Any additional data
+// given before it must be a whole number of blocks, and none may be added
+// forwards once reversed input has begun. It must be called before any
+// plaintext is encrypted.
+func (g *Encrypter) AddADReversed(block []byte) {
+	if g.adDone {
+		panic("gcm: additional data added after plaintext")
+	}
+
+	g.addADReversed(block)
+	g.additionalDataNb += uint64(len(block))
+}
+
+// AddADReversed authenticates additional data supplied one block at a time
+// from the last block to the first. The first call takes the final, possibly
+// partial, block; every later call must be a full block. Any additional data
+// given before it must be a whole number of blocks, and none may be added
+// forwards once reversed input has begun. It must be called before any
+// ciphertext is decrypted.
+func (g *Decrypter) AddADReversed(block []byte) {
+	if g.adDone {
+		panic("gcm: additional data added after ciphertext")
+	}
+
+	g.addADReversed(block)
+	g.additionalDataNb += uint64(len(block))
+}
@@ -0,0 +1,119 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func feedReversed(g interface{ AddADReversed([]byte) }, additionalData []byte) {
+	end := len(additionalData)
+	start := end - end%gcmBlockSize
+	if start == end && end > 0 {
+		start -= gcmBlockSize
+	}
+
+	for end > 0 {
+		g.AddADReversed(additionalData[start:end])
+		end = start
+		start -= gcmBlockSize
+	}
+}
+
+func TestGCMMul(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	g := newGCMEncrypter(block, nonce, nil)
+	h := g.productTable[reverseBits(1)]
+
+	y := gcmFieldElement{0x0123456789abcdef, 0xfedcba9876543210}
+	product := gcmMul(&y, &h)
+
+	g.mul(&y)
+	assert.Equal(t, y, product)
+	assert.Equal(t, h, gcmMul(&gcmOne, &h))
+}
+
+func TestAddADReversed(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	additionalData := make([]byte, 50)
+	for i := range additionalData {
+		additionalData[i] = byte(i)
+	}
+
+	for n := 0; n <= len(additionalData); n++ {
+		forward := newGCMEncrypter(block, nonce, additionalData[:n])
+		forward.Encrypt(nil, decryptedPacket)
+
+		reversed := newGCMEncrypter(block, nonce, nil)
+		feedReversed(reversed, additionalData[:n])
+		reversed.Encrypt(nil, decryptedPacket)
+
+		assert.Equal(t, forward.Tag(), reversed.Tag(), "additional data length %d", n)
+	}
+}
+
+func TestAddADReversedAfterForward(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	additionalData := make([]byte, 40)
+	for i := range additionalData {
+		additionalData[i] = byte(i)
+	}
+
+	enc := newGCMEncrypter(block, nonce, additionalData)
+	ciphertext := enc.Encrypt(nil, decryptedPacket)
+	tag := enc.Tag()
+
+	dec := newGCMDecrypter(block, nonce, additionalData[:gcmBlockSize])
+	feedReversed(dec, additionalData[gcmBlockSize:])
+
+	_, err = dec.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, dec.Verify(tag[:]))
+}
+
+func TestAddADReversedMisuse(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	g := newGCMEncrypter(block, nonce, nil)
+	g.AddADReversed([]byte{1, 2, 3})
+	assert.Panics(t, func() { g.AddADReversed([]byte{1, 2, 3}) })
+
+	g = newGCMEncrypter(block, nonce, nil)
+	g.Encrypt(nil, decryptedPacket)
+	assert.Panics(t, func() { g.AddADReversed(key) })
+}
+
+func TestAddADReversedOrdering(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// Unaligned forward additional data cannot be followed by reversed
+	// blocks without padding it, which would not be GCM over the
+	// concatenation.
+	e := newGCMEncrypter(block, nonce, []byte("abc"))
+	assert.Panics(t, func() { e.AddADReversed(make([]byte, gcmBlockSize)) })
+
+	// Forward additional data once reversed input has begun would be
+	// dropped when the next reversed block is folded in.
+	e = newGCMEncrypter(block, nonce, nil)
+	e.AddADReversed([]byte("tail"))
+	assert.Panics(t, func() { e.AddAdditionalData([]byte("forward")) })
+
+	// Even an empty Encrypt ends the additional data.
+	e = newGCMEncrypter(block, nonce, nil)
+	e.Encrypt(nil, nil)
+	assert.Panics(t, func() { e.AddADReversed([]byte("late")) })
+
+	d := newGCMDecrypter(block, nonce, nil)
+	_, err = d.Decrypt(nil, nil)
+	assert.Nil(t, err)
+	assert.Panics(t, func() { d.AddADReversed([]byte("late")) })
+}
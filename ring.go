@@ -0,0 +1,108 @@
+package uncheckedgcm
+
+import (
+	"errors"
+	"io"
+)
+
+// ErrRingFull is returned by DecryptInto when the ciphertext does not fit in
+// the ring's free space.
+var ErrRingFull = errors.New("gcm: not enough free space in ring buffer")
+
+// RingBuffer is a fixed-size byte queue that DecryptInto writes plaintext
+// into and the consumer drains with Read, so an unbounded stream can be
+// decrypted in constant memory. The zero value is an empty ring with no
+// space; use NewRingBuffer to create a usable one.
+type RingBuffer struct {
+	buf   []byte
+	start int
+	n     int
+}
+
+// NewRingBuffer returns an empty ring holding up to size bytes. It panics if
+// size is not positive.
+func NewRingBuffer(size int) *RingBuffer {
+	if size <= 0 {
+		panic("gcm: ring buffer size must be positive")
+	}
+	return &RingBuffer{buf: make([]byte, size)}
+}
+
+// Len returns the number of buffered bytes not yet read.
+func (r *RingBuffer) Len() int {
+	return r.n
+}
+
+// Free returns the number of bytes that can be written before the ring is
+// full.
+func (r *RingBuffer) Free() int {
+	return len(r.buf) - r.n
+}
+
+// Read copies buffered bytes into p and removes them from the ring. It
+// returns io.EOF when the ring is empty.
+func (r *RingBuffer) Read(p []byte) (int, error) {
+	if r.n == 0 {
+		if len(p) == 0 {
+			return 0, nil
+		}
+		return 0, io.EOF
+	}
+
+	read := 0
+	for len(p) > 0 && r.n > 0 {
+		end := min(r.start+r.n, len(r.buf))
+		c := copy(p, r.buf[r.start:end])
+		clear(r.buf[r.start : r.start+c])
+
+		p = p[c:]
+		read += c
+		r.n -= c
+		r.start = (r.start + c) % len(r.buf)
+	}
+	return read, nil
+}
+
+// free returns the free space as up to two regions in write order: from the
+// write position to the end of the buffer, then from the start of the buffer
+// up to the unread data.
+func (r *RingBuffer) free() (first, second []byte) {
+	if len(r.buf) == 0 {
+		return nil, nil
+	}
+
+	end := (r.start + r.n) % len(r.buf)
+	if r.n > 0 && end <= r.start {
+		return r.buf[end:r.start], nil
+	}
+	return r.buf[end:], r.buf[:r.start]
+}
+
+// DecryptInto decrypts ciphertext into the free space of ring, wrapping
+// around its end as needed, and hashes the ciphertext for a later Verify,
+// exactly as Decrypt would. If ciphertext does not fit in ring.Free(),
+// ErrRingFull is returned and nothing is decrypted.
+func (g *Decrypter) DecryptInto(ring *RingBuffer, ciphertext []byte) error {
+	if g.discarded {
+		return ErrDiscarded
+	}
+	if g.finalized {
+		return ErrFinalized
+	}
+	if len(ciphertext) > ring.Free() {
+		return ErrRingFull
+	}
+	if err := g.checkKeystream(len(ciphertext)); err != nil {
+		return err
+	}
+
+	// The keystream left over in extraMask carries across the split like
+	// across any two Decrypt calls.
+	first, second := ring.free()
+	n := min(len(first), len(ciphertext))
+	g.Decrypt(first[:0], ciphertext[:n])
+	g.Decrypt(second[:0], ciphertext[n:])
+
+	ring.n += len(ciphertext)
+	return nil
+}
@@ -0,0 +1,82 @@
+package uncheckedgcm
+
+import (
+	"io"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecryptInto(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	plaintext := make([]byte, 100000)
+	rng.Read(plaintext)
+
+	e, err := NewFromKey(key, nonce, nil)
+	assert.Nil(t, err)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	// 37 is not a multiple of the block size, so keystream blocks straddle
+	// the wrap point.
+	ring := NewRingBuffer(37)
+	d, err := NewDecrypterFromKey(key, nonce, nil)
+	assert.Nil(t, err)
+
+	var decrypted []byte
+	buf := make([]byte, 37)
+	for len(ciphertext) > 0 || ring.Len() > 0 {
+		if n := min(rng.Intn(ring.Free()+1), len(ciphertext)); n > 0 {
+			assert.Nil(t, d.DecryptInto(ring, ciphertext[:n]))
+			ciphertext = ciphertext[n:]
+		}
+
+		m, err := ring.Read(buf[:rng.Intn(len(buf)+1)])
+		if err != io.EOF {
+			assert.Nil(t, err)
+		}
+		decrypted = append(decrypted, buf[:m]...)
+	}
+
+	assert.Equal(t, plaintext, decrypted)
+	assert.Nil(t, d.VerifyArray(tag))
+}
+
+func TestDecryptIntoFull(t *testing.T) {
+	e, err := NewFromKey(key, nonce, nil)
+	assert.Nil(t, err)
+	ciphertext := e.Encrypt(nil, make([]byte, 20))
+
+	ring := NewRingBuffer(16)
+	d, err := NewDecrypterFromKey(key, nonce, nil)
+	assert.Nil(t, err)
+	assert.Equal(t, ErrRingFull, d.DecryptInto(ring, ciphertext))
+	assert.Zero(t, ring.Len())
+
+	// The failed call consumed no keystream.
+	assert.Nil(t, d.DecryptInto(ring, ciphertext[:16]))
+	out := make([]byte, 16)
+	n, err := ring.Read(out)
+	assert.Nil(t, err)
+	assert.Equal(t, make([]byte, 16), out[:n])
+
+	_, err = ring.Read(out)
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestRingBufferZeroValue(t *testing.T) {
+	var ring RingBuffer
+	assert.Zero(t, ring.Free())
+	assert.Zero(t, ring.Len())
+
+	d, err := NewDecrypterFromKey(key, nonce, nil)
+	assert.Nil(t, err)
+	assert.Nil(t, d.DecryptInto(&ring, nil))
+	assert.Equal(t, ErrRingFull, d.DecryptInto(&ring, []byte{1}))
+
+	_, err = ring.Read(make([]byte, 1))
+	assert.Equal(t, io.EOF, err)
+
+	assert.Panics(t, func() { NewRingBuffer(0) })
+}
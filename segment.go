@@ -0,0 +1,174 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// Segment nonces are the base nonce followed by the segment index as a
+// big-endian uint64 and a flag byte. Appending rather than XORing the index
+// in keeps the nonces of different base nonces disjoint: two base nonces
+// that differ only in their low bytes cannot derive the same segment nonce.
+const (
+	segmentMiddle byte = 0
+	segmentFinal  byte = 1
+)
+
+// segmentNonce returns baseNonce || index || flag.
+func segmentNonce(baseNonce []byte, index uint64, flag byte) []byte {
+	nonce := make([]byte, 0, len(baseNonce)+9)
+	nonce = append(nonce, baseNonce...)
+	nonce = binary.BigEndian.AppendUint64(nonce, index)
+	return append(nonce, flag)
+}
+
+// SegmentWriter splits a stream into independently authenticated segments of
+// up to segSize bytes of plaintext. Each segment is written to the
+// underlying writer in a single Write call as ciphertext||tag, sealed under a
+// nonce derived from the base nonce, the segment's position and whether it
+// is the last segment, as in the STREAM construction. baseNonce must be
+// unique per stream like any other GCM nonce.
+type SegmentWriter struct {
+	w         io.Writer
+	cipher    cipher.Block
+	baseNonce []byte
+	index     uint64
+	buf       []byte
+	segSize   int
+	closed    bool
+}
+
+func newSegmentWriter(w io.Writer, cipher cipher.Block, baseNonce []byte, segSize int) *SegmentWriter {
+	if len(baseNonce) < 8 {
+		panic("gcm: segment base nonce must be at least 8 bytes")
+	}
+	if segSize <= 0 {
+		panic("gcm: segment size must be positive")
+	}
+
+	return &SegmentWriter{
+		w:         w,
+		cipher:    cipher,
+		baseNonce: append([]byte(nil), baseNonce...),
+		buf:       make([]byte, 0, segSize),
+		segSize:   segSize,
+	}
+}
+
+// Write buffers p, emitting a segment each time segSize bytes are available
+// and more data follows them. A full segment is held back until then, since
+// only Close knows which segment is the last. Write returns
+// io.ErrClosedPipe after Close.
+func (s *SegmentWriter) Write(p []byte) (int, error) {
+	if s.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	n := 0
+	for len(p) > 0 {
+		if len(s.buf) == s.segSize {
+			if err := s.flush(segmentMiddle); err != nil {
+				return n, err
+			}
+		}
+
+		m := copy(s.buf[len(s.buf):s.segSize], p)
+		s.buf = s.buf[:len(s.buf)+m]
+		p = p[m:]
+		n += m
+	}
+	return n, nil
+}
+
+// Close emits the buffered plaintext as the final segment, which is empty
+// only if nothing was written. Every stream ends with exactly one final
+// segment, so a reader can tell a complete stream from a truncated one.
+// Close does not close the underlying writer, and calling it again does
+// nothing.
+func (s *SegmentWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	return s.flush(segmentFinal)
+}
+
+func (s *SegmentWriter) flush(flag byte) error {
+	g := newGCMEncrypter(s.cipher, segmentNonce(s.baseNonce, s.index, flag), nil)
+	s.index++
+
+	segment := g.Encrypt(make([]byte, 0, len(s.buf)+gcmTagSize), s.buf)
+	tag := g.Tag()
+	segment = append(segment, tag[:]...)
+	s.buf = s.buf[:0]
+
+	_, err := s.w.Write(segment)
+	return err
+}
+
+var (
+	errSegmentSize       = errors.New("gcm: segment shorter than tag")
+	errSegmentAfterFinal = errors.New("gcm: segment after the final segment")
+)
+
+// SegmentReader opens segments produced by a SegmentWriter one at a time. A
+// segment only verifies under the nonce for its expected position, so lost,
+// duplicated or reordered segments fail with ErrOpen. Once every segment has
+// been opened, Close reports whether the final one was among them.
+type SegmentReader struct {
+	cipher    cipher.Block
+	baseNonce []byte
+	index     uint64
+	done      bool
+}
+
+func newSegmentReader(cipher cipher.Block, baseNonce []byte) *SegmentReader {
+	if len(baseNonce) < 8 {
+		panic("gcm: segment base nonce must be at least 8 bytes")
+	}
+
+	return &SegmentReader{
+		cipher:    cipher,
+		baseNonce: append([]byte(nil), baseNonce...),
+	}
+}
+
+// Open verifies the next segment and appends its plaintext to dst. If the
+// segment does not verify, nothing is appended and the reader keeps waiting
+// for the segment at the same position. Segments after the final one are
+// rejected.
+func (s *SegmentReader) Open(dst, segment []byte) ([]byte, error) {
+	if len(segment) < gcmTagSize {
+		return nil, errSegmentSize
+	}
+	if s.done {
+		return nil, errSegmentAfterFinal
+	}
+
+	ciphertext := segment[:len(segment)-gcmTagSize]
+	tag := segment[len(segment)-gcmTagSize:]
+
+	// The reader cannot tell the final segment apart until it verifies.
+	for _, flag := range []byte{segmentMiddle, segmentFinal} {
+		g := newGCMDecrypter(s.cipher, segmentNonce(s.baseNonce, s.index, flag), nil)
+		plaintext, err := g.OpenDetached(ciphertext, tag)
+		if err == nil {
+			s.index++
+			s.done = flag == segmentFinal
+			return append(dst, plaintext...), nil
+		}
+	}
+
+	return nil, ErrOpen
+}
+
+// Close returns ErrTruncated if the final segment has not been opened, which
+// means trailing segments were lost or dropped.
+func (s *SegmentReader) Close() error {
+	if !s.done {
+		return ErrTruncated
+	}
+	return nil
+}
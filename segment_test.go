@@ -0,0 +1,162 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// datagrams records each Write as a separate packet.
+type datagrams [][]byte
+
+func (d *datagrams) Write(p []byte) (int, error) {
+	*d = append(*d, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func writeSegments(t *testing.T, plaintext []byte, segSize int) datagrams {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var packets datagrams
+	w := newSegmentWriter(&packets, block, nonce, segSize)
+
+	for i := 0; i < len(plaintext); i += 7 {
+		_, err := w.Write(plaintext[i:min(i+7, len(plaintext))])
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, w.Close())
+
+	return packets
+}
+
+func TestSegmentRoundTrip(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 100)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	packets := writeSegments(t, plaintext, 32)
+	assert.Len(t, packets, 4)
+	for _, packet := range packets[:3] {
+		assert.Len(t, packet, 32+gcmTagSize)
+	}
+
+	r := newSegmentReader(block, nonce)
+	var got []byte
+	for _, packet := range packets {
+		got, err = r.Open(got, packet)
+		assert.Nil(t, err)
+	}
+	assert.Equal(t, plaintext, got)
+	assert.Nil(t, r.Close())
+}
+
+func TestSegmentLossAndReorder(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 64)
+	packets := writeSegments(t, plaintext, 16)
+
+	r := newSegmentReader(block, nonce)
+
+	_, err = r.Open(nil, packets[0])
+	assert.Nil(t, err)
+
+	// Segment 1 is lost: segment 2 does not open in its place.
+	_, err = r.Open(nil, packets[2])
+	assert.ErrorIs(t, err, ErrOpen)
+
+	// Replaying segment 0 fails as well.
+	_, err = r.Open(nil, packets[0])
+	assert.ErrorIs(t, err, ErrOpen)
+
+	// Once the missing segment arrives, the rest follow in order.
+	for _, packet := range packets[1:] {
+		_, err = r.Open(nil, packet)
+		assert.Nil(t, err)
+	}
+
+	_, err = r.Open(nil, packets[3][:gcmTagSize-1])
+	assert.ErrorIs(t, err, errSegmentSize)
+}
+
+func TestSegmentTruncation(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// 64 bytes is an exact multiple of the segment size, so the last full
+	// segment is the final one and no empty segment follows it.
+	for _, size := range []int{0, 50, 64} {
+		packets := writeSegments(t, make([]byte, size), 16)
+		assert.Len(t, packets, max(1, (size+15)/16))
+
+		for keep := 0; keep < len(packets); keep++ {
+			r := newSegmentReader(block, nonce)
+			for _, packet := range packets[:keep] {
+				_, err := r.Open(nil, packet)
+				assert.Nil(t, err)
+			}
+			assert.Equal(t, ErrTruncated, r.Close(), "size %d, kept %d", size, keep)
+		}
+
+		r := newSegmentReader(block, nonce)
+		for _, packet := range packets {
+			_, err := r.Open(nil, packet)
+			assert.Nil(t, err)
+		}
+		assert.Nil(t, r.Close())
+
+		// Nothing may follow the final segment, not even a replay of it.
+		_, err = r.Open(nil, packets[len(packets)-1])
+		assert.Equal(t, errSegmentAfterFinal, err)
+	}
+}
+
+func TestSegmentWriterClosed(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var packets datagrams
+	w := newSegmentWriter(&packets, block, nonce, 16)
+	assert.Nil(t, w.Close())
+	assert.Nil(t, w.Close())
+	assert.Len(t, packets, 1)
+
+	_, err = w.Write([]byte{1})
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func TestSegmentRelatedBaseNonces(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// Base nonces differing only in their low bytes must not share any
+	// segment keystream.
+	related := append([]byte(nil), nonce...)
+	related[len(related)-1] ^= 1
+
+	plaintext := make([]byte, 8*16)
+	var a, b datagrams
+	for _, out := range []struct {
+		base    []byte
+		packets *datagrams
+	}{{nonce, &a}, {related, &b}} {
+		w := newSegmentWriter(out.packets, block, out.base, 16)
+		_, err := w.Write(plaintext)
+		assert.Nil(t, err)
+		assert.Nil(t, w.Close())
+	}
+
+	for i := range a {
+		for j := range b {
+			assert.NotEqual(t, a[i][:16], b[j][:16], "segments %d and %d", i, j)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+type gcmTestVector struct {
+	key, nonce, plaintext, additionalData, ciphertext, tag string
+}
+
+// gcmTestVectors returns test cases 1-6 from "The Galois/Counter Mode of
+// Operation (GCM)" by McGrew and Viega, as published with NIST SP 800-38D.
+// Cases 5 and 6 use 8- and 60-byte nonces, which go through the GHASH
+// counter derivation like this package's 16-byte nonces. Each call returns a
+// fresh slice, so a caller cannot change what SelfTest checks.
+func gcmTestVectors() []gcmTestVector {
+	return []gcmTestVector{
+		{
+			key:   "00000000000000000000000000000000",
+			nonce: "000000000000000000000000",
+			tag:   "58e2fccefa7e3061367f1d57a4e7455a",
+		},
+		{
+			key:        "00000000000000000000000000000000",
+			nonce:      "000000000000000000000000",
+			plaintext:  "00000000000000000000000000000000",
+			ciphertext: "0388dace60b6a392f328c2b971b2fe78",
+			tag:        "ab6e47d42cec13bdf53a67b21257bddf",
+		},
+		{
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b391aafd255",
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+				"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091473f5985",
+			tag: "4d5c2af327cd64a62cf35abd2ba6fab4",
+		},
+		{
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbaddecaf888",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			additionalData: "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e" +
+				"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091",
+			tag: "5bc94fbc3221a5db94fae95ae7121a47",
+		},
+		{
+			key:   "feffe9928665731c6d6a8f9467308308",
+			nonce: "cafebabefacedbad",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			additionalData: "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "61353b4c2806934a777ff51fa22a4755699b2a714fcdc6f83766e5f97b6c7423" +
+				"73806900e49f24b22b097544d4896b424989b5e1ebac0f07c23f4598",
+			tag: "3612d2e79e3b0785561be14aaca2fccb",
+		},
+		{
+			key: "feffe9928665731c6d6a8f9467308308",
+			nonce: "9313225df88406e555909c5aff5269aa6a7a9538534f7da1e4c303d2a318a728" +
+				"c3c0c95156809539fcf0e2429a6b525416aedbf5a0de6a57a637b39b",
+			plaintext: "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72" +
+				"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39",
+			additionalData: "feedfacedeadbeeffeedfacedeadbeefabaddad2",
+			ciphertext: "8ce24998625615b603a033aca13fb894be9112a5c3a211a8ba262a3cca7e2ca7" +
+				"01e4a9a4fba43c90ccdcb281d48c7c6fd62875d2aca417034c34aee5",
+			tag: "619cc5aefffe0bfa462af43c1699d050",
+		},
+	}
+}
+
+// SelfTest runs known-answer tests for encryption and decryption, in the
+// style of a power-on self-test. It checks test cases 4 and 6 of
+// gcmTestVectors, which cover additional data and both the 12-byte and the
+// GHASH-based counter derivation, and returns an error if any output differs.
+// Built with the ugcm_selftest tag, the package runs it at init and panics if
+// it fails.
+func SelfTest() error {
+	return selfTest(gcmTestVectors())
+}
+
+// selfTest runs the known-answer tests against cases 4 and 6 of vectors.
+func selfTest(vectors []gcmTestVector) error {
+	for _, i := range []int{3, 5} {
+		if err := selfTestVector(vectors[i]); err != nil {
+			return fmt.Errorf("%w (self-test case %d)", err, i+1)
+		}
+	}
+	return nil
+}
+
+var errSelfTest = errors.New("gcm: known-answer test failed")
+
+func selfTestVector(tv gcmTestVector) error {
+	key, _ := hex.DecodeString(tv.key)
+	nonce, _ := hex.DecodeString(tv.nonce)
+	plaintext, _ := hex.DecodeString(tv.plaintext)
+	additionalData, _ := hex.DecodeString(tv.additionalData)
+	ciphertext, _ := hex.DecodeString(tv.ciphertext)
+	tag, _ := hex.DecodeString(tv.tag)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return err
+	}
+
+	enc := newGCMEncrypter(block, nonce, additionalData)
+	gotCiphertext := enc.Encrypt(nil, plaintext)
+	gotTag := enc.Tag()
+	if subtle.ConstantTimeCompare(gotCiphertext, ciphertext) != 1 ||
+		subtle.ConstantTimeCompare(gotTag[:], tag) != 1 {
+		return errSelfTest
+	}
+
+	dec := newGCMDecrypter(block, nonce, additionalData)
+	gotPlaintext, err := dec.Decrypt(nil, ciphertext)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare(gotPlaintext, plaintext) != 1 {
+		return errSelfTest
+	}
+	return dec.Verify(tag)
+}
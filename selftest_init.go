@@ -0,0 +1,9 @@
+//go:build ugcm_selftest
+
+package uncheckedgcm
+
+func init() {
+	if err := SelfTest(); err != nil {
+		panic(err)
+	}
+}
@@ -0,0 +1,21 @@
+package uncheckedgcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelfTest(t *testing.T) {
+	assert.Nil(t, SelfTest())
+}
+
+func TestSelfTestDetectsFailure(t *testing.T) {
+	vectors := gcmTestVectors()
+	vectors[5].tag = "719cc5aefffe0bfa462af43c1699d050"
+
+	err := selfTest(vectors)
+	assert.ErrorIs(t, err, errSelfTest)
+	assert.EqualError(t, err, "gcm: known-answer test failed (self-test case 6)")
+	assert.Nil(t, SelfTest())
+}
@@ -0,0 +1,42 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"errors"
+	"sync/atomic"
+)
+
+// ErrConsumed is returned by SingleUse.Seal after its first call.
+var ErrConsumed = errors.New("gcm: single-use sealer already used")
+
+// SingleUse seals exactly one message under a nonce it draws from
+// crypto/rand itself, so a nonce can never be reused by mistake. It is safe
+// for concurrent use; only one call to Seal ever succeeds.
+type SingleUse struct {
+	cipher cipher.Block
+	used   atomic.Bool
+}
+
+// NewSingleUse returns a SingleUse that seals its one message under cipher.
+func NewSingleUse(cipher cipher.Block) *SingleUse {
+	return &SingleUse{cipher: cipher}
+}
+
+// Seal encrypts plaintext and returns nonce||ciphertext||tag. Every call
+// after the first returns ErrConsumed, as does the first if no nonce could
+// be generated.
+func (s *SingleUse) Seal(plaintext, additionalData []byte) ([]byte, error) {
+	if s.used.Swap(true) {
+		return nil, ErrConsumed
+	}
+
+	g, nonce, err := newGCMEncrypterRandomNonce(s.cipher, nil, additionalData)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, len(nonce)+len(plaintext)+gcmTagSize)
+	out = append(out, nonce...)
+	out = g.Encrypt(out, plaintext)
+	return g.Sum(out), nil
+}
@@ -0,0 +1,45 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSingleUse(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	s := NewSingleUse(block)
+	sealed, err := s.Seal(decryptedPacket, []byte("ad"))
+	assert.Nil(t, err)
+	assert.Len(t, sealed, gcmNonceSize+len(decryptedPacket)+gcmTagSize)
+
+	n := sealed[:gcmNonceSize]
+	ciphertext := sealed[gcmNonceSize : len(sealed)-gcmTagSize]
+	tag := sealed[len(sealed)-gcmTagSize:]
+	plaintext, err := newGCMDecrypter(block, n, []byte("ad")).OpenVerified(ciphertext, tag)
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+
+	_, err = s.Seal(decryptedPacket, []byte("ad"))
+	assert.Equal(t, ErrConsumed, err)
+	_, err = s.Seal(nil, nil)
+	assert.Equal(t, ErrConsumed, err)
+}
+
+func TestSingleUseDistinctNonces(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		sealed, err := NewSingleUse(block).Seal(nil, nil)
+		assert.Nil(t, err)
+
+		nonce := string(sealed[:gcmNonceSize])
+		assert.False(t, seen[nonce])
+		seen[nonce] = true
+	}
+}
@@ -0,0 +1,42 @@
+package uncheckedgcm
+
+import "errors"
+
+// ErrWeakParameters is returned by the strict constructors for an all-zero
+// key or nonce, which almost always means a buffer was never filled in.
+var ErrWeakParameters = errors.New("gcm: all-zero key or nonce")
+
+// NewFromKeyStrict is like NewFromKey but also rejects an all-zero key,
+// whose hash subkey is public, and an all-zero nonce with ErrWeakParameters.
+func NewFromKeyStrict(key, nonce, additionalData []byte) (*Encrypter, error) {
+	if err := checkStrict(key, nonce); err != nil {
+		return nil, err
+	}
+	return NewFromKey(key, nonce, additionalData)
+}
+
+// NewDecrypterFromKeyStrict is the decrypting counterpart of
+// NewFromKeyStrict.
+func NewDecrypterFromKeyStrict(key, nonce, additionalData []byte) (*Decrypter, error) {
+	if err := checkStrict(key, nonce); err != nil {
+		return nil, err
+	}
+	return NewDecrypterFromKey(key, nonce, additionalData)
+}
+
+func checkStrict(key, nonce []byte) error {
+	if isZero(key) || isZero(nonce) {
+		return ErrWeakParameters
+	}
+	return nil
+}
+
+// isZero reports whether b is empty or all zero bytes, in time that depends
+// only on len(b).
+func isZero(b []byte) bool {
+	var acc byte
+	for _, v := range b {
+		acc |= v
+	}
+	return acc == 0
+}
@@ -0,0 +1,36 @@
+package uncheckedgcm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStrict(t *testing.T) {
+	zeroKey := make([]byte, 16)
+	zeroNonce := make([]byte, gcmNonceSize)
+
+	_, err := NewFromKeyStrict(zeroKey, nonce, nil)
+	assert.ErrorIs(t, err, ErrWeakParameters)
+	_, err = NewFromKeyStrict(key, zeroNonce, nil)
+	assert.ErrorIs(t, err, ErrWeakParameters)
+	_, err = NewDecrypterFromKeyStrict(zeroKey, nonce, nil)
+	assert.ErrorIs(t, err, ErrWeakParameters)
+	_, err = NewDecrypterFromKeyStrict(key, zeroNonce[:gcmStandardNonceSize], nil)
+	assert.ErrorIs(t, err, ErrWeakParameters)
+
+	// A single set bit is enough.
+	oneBit := make([]byte, 16)
+	oneBit[15] = 1
+	_, err = NewFromKeyStrict(oneBit, nonce, nil)
+	assert.Nil(t, err)
+
+	_, err = NewFromKeyStrict(key, nonce, nil)
+	assert.Nil(t, err)
+	_, err = NewDecrypterFromKeyStrict(key, nonce, nil)
+	assert.Nil(t, err)
+
+	// Strict checks are opt-in.
+	_, err = NewFromKey(zeroKey, zeroNonce, nil)
+	assert.Nil(t, err)
+}
@@ -0,0 +1,60 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"io"
+)
+
+// TagFirstEncrypter produces tag||ciphertext, for formats that send the tag
+// before the data it covers. The tag depends on every ciphertext block, so
+// nothing can be emitted until all plaintext has been written. Plaintext is
+// encrypted as it arrives and only the ciphertext is held in memory.
+//
+// The receiver reads the tag first, passes it to SetExpectedTag, and then
+// streams the ciphertext through Decrypt, checking it with VerifyExpected.
+type TagFirstEncrypter struct {
+	g          *Encrypter
+	ciphertext []byte
+	done       bool
+}
+
+var _ io.WriterTo = (*TagFirstEncrypter)(nil)
+
+// NewTagFirstEncrypter returns a TagFirstEncrypter for the given nonce and
+// additional data.
+func NewTagFirstEncrypter(cipher cipher.Block, nonce, additionalData []byte) *TagFirstEncrypter {
+	return &TagFirstEncrypter{
+		g: newGCMEncrypter(cipher, nonce, additionalData),
+	}
+}
+
+// Write encrypts p into the internal buffer. It returns io.ErrClosedPipe
+// once WriteTo has been called.
+func (e *TagFirstEncrypter) Write(p []byte) (int, error) {
+	if e.done {
+		return 0, io.ErrClosedPipe
+	}
+
+	e.ciphertext = e.g.Encrypt(e.ciphertext, p)
+	return len(p), nil
+}
+
+// WriteTo finalizes the message and writes the tag followed by the
+// ciphertext to w. It can only be called once; later calls return
+// io.ErrClosedPipe.
+func (e *TagFirstEncrypter) WriteTo(w io.Writer) (int64, error) {
+	if e.done {
+		return 0, io.ErrClosedPipe
+	}
+	e.done = true
+
+	tag := e.g.Tag()
+	n, err := w.Write(tag[:])
+	if err != nil {
+		return int64(n), err
+	}
+
+	m, err := w.Write(e.ciphertext)
+	e.ciphertext = nil
+	return int64(n + m), err
+}
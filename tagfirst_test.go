@@ -0,0 +1,66 @@
+package uncheckedgcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTagFirstRoundTrip(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := NewTagFirstEncrypter(block, nonce, []byte("ad"))
+	for i := 0; i < len(decryptedPacket); i += 7 {
+		_, err := e.Write(decryptedPacket[i:min(i+7, len(decryptedPacket))])
+		assert.Nil(t, err)
+	}
+
+	var wire bytes.Buffer
+	n, err := e.WriteTo(&wire)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(gcmTagSize+len(decryptedPacket)), n)
+
+	// The layout is the standard tag and ciphertext, reordered.
+	reference := newGCMEncrypter(block, nonce, []byte("ad"))
+	ciphertext := reference.Encrypt(nil, decryptedPacket)
+	referenceTag := reference.Tag()
+	assert.Equal(t, append(referenceTag[:], ciphertext...), wire.Bytes())
+
+	// The receiver reads the tag, then streams the ciphertext.
+	d := newGCMDecrypter(block, nonce, []byte("ad"))
+	tag := make([]byte, gcmTagSize)
+	_, err = io.ReadFull(&wire, tag)
+	assert.Nil(t, err)
+	d.SetExpectedTag(tag)
+
+	var plaintext []byte
+	chunk := make([]byte, 5)
+	for {
+		n, err := wire.Read(chunk)
+		if err == io.EOF {
+			break
+		}
+		plaintext, err = d.Decrypt(plaintext, chunk[:n])
+		assert.Nil(t, err)
+	}
+	assert.Nil(t, d.VerifyExpected())
+	assert.Equal(t, decryptedPacket, plaintext)
+}
+
+func TestTagFirstClosed(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := NewTagFirstEncrypter(block, nonce, nil)
+	_, err = e.WriteTo(io.Discard)
+	assert.Nil(t, err)
+
+	_, err = e.Write(decryptedPacket)
+	assert.Equal(t, io.ErrClosedPipe, err)
+	_, err = e.WriteTo(io.Discard)
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
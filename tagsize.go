@@ -0,0 +1,38 @@
+package uncheckedgcm
+
+import "crypto/subtle"
+
+// VerifyAny is like Verify but also accepts a tag truncated to 12 bytes, for
+// fleets where some peers send full tags and others truncated ones. The
+// size is taken from len(tag), which is public; for either size the whole
+// candidate is compared in constant time. Any other size returns ErrOpen.
+//
+// Accepting 12-byte tags lowers the forgery bound for every message checked
+// this way, not just those sent by truncating peers.
+func (g *Decrypter) VerifyAny(tag []byte) error {
+	if g.discarded {
+		return ErrDiscarded
+	}
+
+	err := g.verifyAny(tag)
+	if g.observer != nil {
+		g.observer.OnVerify(err == nil, g.ciphertextNb)
+	}
+	return err
+}
+
+func (g *Decrypter) verifyAny(tag []byte) error {
+	if err := g.checkLength(); err != nil {
+		return err
+	}
+
+	if len(tag) != gcmTagSize && len(tag) != gcmMinimumTagSize {
+		return ErrOpen
+	}
+
+	expected := g.Tag()
+	if subtle.ConstantTimeCompare(expected[:len(tag)], tag) != 1 {
+		return ErrOpen
+	}
+	return nil
+}
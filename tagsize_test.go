@@ -0,0 +1,39 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyAny(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, decryptedPacket)
+	tag := e.Tag()
+
+	verify := func(tag []byte) error {
+		d := newGCMDecrypter(block, nonce, nil)
+		_, err := d.Decrypt(nil, ciphertext)
+		assert.Nil(t, err)
+		return d.VerifyAny(tag)
+	}
+
+	assert.Nil(t, verify(tag[:]))
+	assert.Nil(t, verify(tag[:12]))
+
+	for _, n := range []int{0, 4, 8, 11, 13, 15} {
+		assert.Equal(t, ErrOpen, verify(tag[:n]), "size %d", n)
+	}
+
+	for _, n := range []int{12, 16} {
+		for i := 0; i < n; i++ {
+			forged := append([]byte(nil), tag[:n]...)
+			forged[i] ^= 1
+			assert.Equal(t, ErrOpen, verify(forged), "size %d byte %d", n, i)
+		}
+	}
+}
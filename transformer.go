@@ -0,0 +1,65 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"io"
+)
+
+// Transformer encrypts everything written to it onto an underlying writer
+// and appends the tag when closed, so a stream can be sealed with io.Copy.
+// Pair it with io.Pipe to read the ciphertext from another goroutine.
+type Transformer struct {
+	w      io.Writer
+	g      *Encrypter
+	buf    []byte
+	err    error
+	closed bool
+}
+
+var _ io.WriteCloser = (*Transformer)(nil)
+
+// NewTransformer returns a Transformer that writes the encryption of
+// everything written to it, followed by the tag, to w.
+func NewTransformer(w io.Writer, cipher cipher.Block, nonce, additionalData []byte) *Transformer {
+	return &Transformer{
+		w: w,
+		g: newGCMEncrypter(cipher, nonce, additionalData),
+	}
+}
+
+// Write encrypts p and writes the ciphertext to the underlying writer. After
+// a write error, every later call returns the same error.
+func (t *Transformer) Write(p []byte) (int, error) {
+	if t.err != nil {
+		return 0, t.err
+	}
+	if t.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	t.buf = t.g.Encrypt(t.buf[:0], p)
+	if _, err := t.w.Write(t.buf); err != nil {
+		t.err = err
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Close writes the tag to the underlying writer. It does not close the
+// underlying writer. Calling Close more than once has no further effect.
+func (t *Transformer) Close() error {
+	if t.err != nil {
+		return t.err
+	}
+	if t.closed {
+		return nil
+	}
+	t.closed = true
+
+	tag := t.g.Tag()
+	if _, err := t.w.Write(tag[:]); err != nil {
+		t.err = err
+		return err
+	}
+	return nil
+}
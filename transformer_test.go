@@ -0,0 +1,75 @@
+package uncheckedgcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransformerCopy(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 1<<20+3)
+	for i := range plaintext {
+		plaintext[i] = byte(i * 7)
+	}
+
+	var out bytes.Buffer
+	tr := NewTransformer(&out, block, nonce, []byte("ad"))
+	n, err := io.Copy(tr, bytes.NewReader(plaintext))
+	assert.Nil(t, err)
+	assert.Equal(t, int64(len(plaintext)), n)
+	assert.Nil(t, tr.Close())
+	assert.Nil(t, tr.Close())
+	assert.Equal(t, len(plaintext)+gcmTagSize, out.Len())
+
+	sealed := out.Bytes()
+	got, err := newGCMDecrypter(block, nonce, []byte("ad")).OpenVerified(sealed[:len(plaintext)], sealed[len(plaintext):])
+	assert.Nil(t, err)
+	assert.True(t, bytes.Equal(plaintext, got))
+
+	_, err = tr.Write([]byte("late"))
+	assert.Equal(t, io.ErrClosedPipe, err)
+}
+
+func TestTransformerPipe(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	pr, pw := io.Pipe()
+	go func() {
+		tr := NewTransformer(pw, block, nonce, nil)
+		_, err := io.Copy(tr, bytes.NewReader(decryptedPacket))
+		if err == nil {
+			err = tr.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	sealed, err := io.ReadAll(pr)
+	assert.Nil(t, err)
+
+	n := len(sealed) - gcmTagSize
+	got, err := newGCMDecrypter(block, nonce, nil).OpenVerified(sealed[:n], sealed[n:])
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, got)
+}
+
+func TestTransformerWriteError(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	errBroken := errors.New("broken")
+	pr, pw := io.Pipe()
+	pr.CloseWithError(errBroken)
+
+	tr := NewTransformer(pw, block, nonce, nil)
+	_, err = tr.Write([]byte("data"))
+	assert.ErrorIs(t, err, errBroken)
+	assert.ErrorIs(t, tr.Close(), errBroken)
+}
@@ -0,0 +1,88 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+)
+
+// Segmented sealing splits a message into fixed-size segments, each sealed
+// with its own tag, and authenticates the list of segment tags with a root
+// tag, a GMAC over their concatenation. A corrupted message can then be
+// traced to the segment that fails, while the root tag still binds the
+// segments' number and order.
+//
+// Segment i of n is sealed under segmentNonce(baseNonce, i, segmentMiddle)
+// and the root under segmentNonce(baseNonce, n, segmentFinal). The index is
+// appended to baseNonce rather than XORed into it, so messages whose base
+// nonces differ only in their low bytes still use disjoint nonces, and
+// baseNonce must be unique per message like any other GCM nonce.
+
+// SealSegments encrypts plaintext in segments of segSize bytes and returns
+// the ciphertext, the tag of each segment and the root tag.
+func SealSegments(block cipher.Block, baseNonce, plaintext []byte, segSize int) ([]byte, [][gcmTagSize]byte, [gcmTagSize]byte) {
+	checkSegmentParams(baseNonce, segSize)
+
+	base := newGCM(block)
+	ciphertext := make([]byte, 0, len(plaintext))
+	var tags [][gcmTagSize]byte
+
+	for i := 0; i*segSize < len(plaintext); i++ {
+		segment := plaintext[i*segSize : min((i+1)*segSize, len(plaintext))]
+
+		e := base.fork().newEncrypter(segmentNonce(baseNonce, uint64(i), segmentMiddle), nil)
+		ciphertext = e.Encrypt(ciphertext, segment)
+		tags = append(tags, e.Tag())
+	}
+
+	return ciphertext, tags, rootTag(base, baseNonce, tags)
+}
+
+// OpenSegments verifies and decrypts a message sealed by SealSegments. If
+// the root tag does not cover tags, it returns ErrOpen. If a segment does not
+// match its tag, it returns a *RecordError carrying the segment's index and
+// ErrOpen. No plaintext is returned unless every segment verifies.
+func OpenSegments(block cipher.Block, baseNonce, ciphertext []byte, segSize int, tags [][gcmTagSize]byte, root [gcmTagSize]byte) ([]byte, error) {
+	checkSegmentParams(baseNonce, segSize)
+
+	base := newGCM(block)
+	want := rootTag(base, baseNonce, tags)
+	if subtle.ConstantTimeCompare(want[:], root[:]) != 1 {
+		return nil, ErrOpen
+	}
+
+	if (len(ciphertext)+segSize-1)/segSize != len(tags) {
+		return nil, ErrOpen
+	}
+
+	plaintext := make([]byte, 0, len(ciphertext))
+	for i, tag := range tags {
+		segment := ciphertext[i*segSize : min((i+1)*segSize, len(ciphertext))]
+
+		d := base.fork().newDecrypter(segmentNonce(baseNonce, uint64(i), segmentMiddle), nil)
+		plaintext, _ = d.Decrypt(plaintext, segment)
+		if err := d.VerifyArray(tag); err != nil {
+			clear(plaintext)
+			return nil, &RecordError{Index: i, Err: err}
+		}
+	}
+
+	return plaintext, nil
+}
+
+func checkSegmentParams(baseNonce []byte, segSize int) {
+	if len(baseNonce) < 8 {
+		panic("gcm: segment base nonce must be at least 8 bytes")
+	}
+	if segSize <= 0 {
+		panic("gcm: segment size must be positive")
+	}
+}
+
+// rootTag returns the GMAC of the concatenated segment tags.
+func rootTag(base *gcm, baseNonce []byte, tags [][gcmTagSize]byte) [gcmTagSize]byte {
+	e := base.fork().newEncrypter(segmentNonce(baseNonce, uint64(len(tags)), segmentFinal), nil)
+	for _, tag := range tags {
+		e.AddAdditionalData(tag[:])
+	}
+	return e.Tag()
+}
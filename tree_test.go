@@ -0,0 +1,92 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSegmentsRoundTrip(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, size := range []int{0, 1, 63, 64, 65, 300} {
+		plaintext := make([]byte, size)
+		for i := range plaintext {
+			plaintext[i] = byte(i)
+		}
+
+		ciphertext, tags, root := SealSegments(block, nonce, plaintext, 64)
+		assert.Len(t, tags, (size+63)/64)
+
+		got, err := OpenSegments(block, nonce, ciphertext, 64, tags, root)
+		assert.Nil(t, err)
+		assert.Equal(t, plaintext, got)
+	}
+}
+
+func TestSegmentsLocateCorruption(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 300)
+	ciphertext, tags, root := SealSegments(block, nonce, plaintext, 64)
+
+	for i := range tags {
+		corrupted := append([]byte(nil), ciphertext...)
+		corrupted[i*64+5] ^= 1
+
+		_, err := OpenSegments(block, nonce, corrupted, 64, tags, root)
+		var recordErr *RecordError
+		assert.ErrorAs(t, err, &recordErr)
+		assert.Equal(t, i, recordErr.Index)
+		assert.ErrorIs(t, err, ErrOpen)
+	}
+}
+
+func TestSegmentsRootBindsTags(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 300)
+	ciphertext, tags, root := SealSegments(block, nonce, plaintext, 64)
+
+	// Swapping two segments along with their tags is caught by the root.
+	swapped := append([]byte(nil), ciphertext...)
+	copy(swapped[:64], ciphertext[64:128])
+	copy(swapped[64:128], ciphertext[:64])
+	swappedTags := append([][gcmTagSize]byte(nil), tags...)
+	swappedTags[0], swappedTags[1] = swappedTags[1], swappedTags[0]
+	_, err = OpenSegments(block, nonce, swapped, 64, swappedTags, root)
+	assert.Equal(t, ErrOpen, err)
+
+	// Dropping the last segment is caught too.
+	_, err = OpenSegments(block, nonce, ciphertext[:256], 64, tags[:4], root)
+	assert.Equal(t, ErrOpen, err)
+
+	root[0] ^= 1
+	_, err = OpenSegments(block, nonce, ciphertext, 64, tags, root)
+	assert.Equal(t, ErrOpen, err)
+}
+
+func TestSegmentsRelatedBaseNonces(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	// Base nonces differing only in their low bytes must not share any
+	// segment keystream.
+	related := append([]byte(nil), nonce...)
+	related[len(related)-1] ^= 1
+
+	plaintext := make([]byte, 8*16)
+	a, _, rootA := SealSegments(block, nonce, plaintext, 16)
+	b, _, rootB := SealSegments(block, related, plaintext, 16)
+
+	for i := 0; i < len(a); i += 16 {
+		for j := 0; j < len(b); j += 16 {
+			assert.NotEqual(t, a[i:i+16], b[j:j+16], "segments %d and %d", i/16, j/16)
+		}
+	}
+	assert.NotEqual(t, rootA, rootB)
+}
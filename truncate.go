@@ -0,0 +1,42 @@
+package uncheckedgcm
+
+import "errors"
+
+// ErrTruncated is returned by Verify when fewer ciphertext bytes were
+// processed than declared with ExpectedLength, and by SegmentReader.Close
+// when the final segment was never opened.
+var ErrTruncated = errors.New("gcm: ciphertext shorter than expected")
+
+// ExpectedLength declares that the message carries n bytes of ciphertext.
+// If Verify is called after fewer bytes have been decrypted, it returns
+// ErrTruncated instead of checking the tag, so a stream cut short can be told
+// apart from a forged one.
+func (g *Decrypter) ExpectedLength(n uint64) {
+	g.expectedNb = n
+	g.expectLength = true
+}
+
+// ErrLengthMismatch is returned by Verify when the number of ciphertext
+// bytes processed differs from the length given to BindLength.
+var ErrLengthMismatch = errors.New("gcm: ciphertext length does not match bound length")
+
+// BindLength declares the exact ciphertext length, typically taken from a
+// length field that is itself authenticated as additional data. Verify then
+// returns ErrLengthMismatch, before checking the tag, if more or fewer bytes
+// were decrypted.
+func (g *Decrypter) BindLength(authenticatedLen uint64) {
+	g.boundNb = authenticatedLen
+	g.bindLength = true
+}
+
+// checkLength reports whether the ciphertext processed so far is consistent
+// with ExpectedLength and BindLength.
+func (g *Decrypter) checkLength() error {
+	if g.expectLength && g.ciphertextNb < g.expectedNb {
+		return ErrTruncated
+	}
+	if g.bindLength && g.ciphertextNb != g.boundNb {
+		return ErrLengthMismatch
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpectedLength(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, make([]byte, 100))
+	tag := e.Tag()
+
+	// The full stream verifies.
+	d := newGCMDecrypter(block, nonce, nil)
+	d.ExpectedLength(uint64(len(ciphertext)))
+	_, err = d.Decrypt(nil, ciphertext)
+	assert.Nil(t, err)
+	assert.Nil(t, d.VerifyArray(tag))
+
+	// A stream cut short is reported as truncated, even if the tag that
+	// arrived happens to be correct for the shorter message.
+	short := newGCMEncrypter(block, nonce, nil)
+	short.Encrypt(nil, make([]byte, 60))
+	shortTag := short.Tag()
+
+	d = newGCMDecrypter(block, nonce, nil)
+	d.ExpectedLength(uint64(len(ciphertext)))
+	_, err = d.Decrypt(nil, ciphertext[:60])
+	assert.Nil(t, err)
+	assert.ErrorIs(t, d.VerifyArray(shortTag), ErrTruncated)
+
+	// Without the hint, truncation is indistinguishable from a short message.
+	d = newGCMDecrypter(block, nonce, nil)
+	_, err = d.Decrypt(nil, ciphertext[:60])
+	assert.Nil(t, err)
+	assert.Nil(t, d.VerifyArray(shortTag))
+}
+
+func TestBindLength(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	var header [4]byte
+	binary.BigEndian.PutUint32(header[:], 64)
+
+	e := newGCMEncrypter(block, nonce, header[:])
+	ciphertext := e.Encrypt(nil, make([]byte, 80))
+	tag := e.Tag()
+
+	verify := func(n int, tag [gcmTagSize]byte) error {
+		d := newGCMDecrypter(block, nonce, header[:])
+		d.BindLength(uint64(binary.BigEndian.Uint32(header[:])))
+		_, err := d.Decrypt(nil, ciphertext[:n])
+		assert.Nil(t, err)
+		return d.VerifyArray(tag)
+	}
+
+	// The header promised 64 bytes; feeding fewer or more is reported as a
+	// length mismatch rather than a forgery.
+	assert.ErrorIs(t, verify(63, tag), ErrLengthMismatch)
+	assert.ErrorIs(t, verify(80, tag), ErrLengthMismatch)
+
+	e = newGCMEncrypter(block, nonce, header[:])
+	e.Encrypt(nil, make([]byte, 64))
+	tag = e.Tag()
+	assert.Nil(t, verify(64, tag))
+
+	tag[0] ^= 1
+	assert.ErrorIs(t, verify(64, tag), ErrOpen)
+}
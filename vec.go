@@ -0,0 +1,32 @@
+package uncheckedgcm
+
+// EncryptVec encrypts each of plaintexts in order and appends the ciphertext
+// to dst, as if they had been concatenated and passed to Encrypt. It suits
+// data that is already split up, such as net.Buffers, without copying it
+// into one slice first.
+func (g *Encrypter) EncryptVec(dst []byte, plaintexts ...[]byte) []byte {
+	n := 0
+	for _, plaintext := range plaintexts {
+		n += len(plaintext)
+	}
+
+	ret, out := sliceForAppend(dst, n)
+	for _, plaintext := range plaintexts {
+		if inexactOverlap(out, plaintext) {
+			panic("gcm: invalid buffer overlap")
+		}
+	}
+
+	for _, plaintext := range plaintexts {
+		g.Encrypt(out[:0], plaintext)
+		out = out[len(plaintext):]
+	}
+
+	return ret
+}
+
+// EncryptN is Encrypt that also returns the number of bytes it appended,
+// which is always len(plaintext).
+func (g *Encrypter) EncryptN(dst, plaintext []byte) (ret []byte, n int) {
+	return g.Encrypt(dst, plaintext), len(plaintext)
+}
@@ -0,0 +1,59 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptVec(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	for _, sizes := range [][]int{
+		{},
+		{0, 0},
+		{5, 11, 16},
+		{15, 2, 31, 1},
+		{17, 0, 33, 48, 3},
+	} {
+		var plaintexts [][]byte
+		var concat []byte
+		for _, size := range sizes {
+			segment := make([]byte, size)
+			for i := range segment {
+				segment[i] = byte(len(concat) + i)
+			}
+			plaintexts = append(plaintexts, segment)
+			concat = append(concat, segment...)
+		}
+
+		want := newGCMEncrypter(block, nonce, nil)
+		wantCiphertext := want.Encrypt([]byte("prefix"), concat)
+
+		g := newGCMEncrypter(block, nonce, nil)
+		ciphertext := g.EncryptVec([]byte("prefix"), plaintexts...)
+
+		assert.Equal(t, wantCiphertext, ciphertext)
+		assert.Equal(t, want.Tag(), g.Tag())
+	}
+}
+
+func TestEncryptN(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	g := newGCMEncrypter(block, nonce, nil)
+
+	buf := []byte("header")
+	total := 0
+	for _, size := range []int{0, 3, 16, 1} {
+		var n int
+		buf, n = g.EncryptN(buf, decryptedPacket[total:total+size])
+		assert.Equal(t, size, n)
+		assert.Equal(t, encryptedPacket[total:total+n], buf[len(buf)-n:])
+		total += n
+	}
+	assert.Equal(t, len("header")+total, len(buf))
+}
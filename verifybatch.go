@@ -0,0 +1,61 @@
+package uncheckedgcm
+
+import (
+	"runtime"
+	"sync"
+)
+
+// VerifyBatch checks the tag of each record under key, spreading the work
+// across GOMAXPROCS goroutines, and returns one error per record: nil if it
+// verifies, ErrOpen if it does not, or the reason its nonce is invalid.
+// Records are taken to have no additional data. The key schedule and
+// product table are built once and shared; the ciphertext is only hashed,
+// never decrypted. If key is not a valid AES key, every entry holds that
+// error.
+func VerifyBatch(key []byte, records []Record) []error {
+	errs := make([]error, len(records))
+
+	k, err := NewKey(key)
+	if err != nil {
+		for i := range errs {
+			errs[i] = err
+		}
+		return errs
+	}
+
+	indices := make(chan int)
+	var wg sync.WaitGroup
+
+	for range min(runtime.GOMAXPROCS(0), len(records)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				errs[i] = k.base.verifyRecord(records[i])
+			}
+		}()
+	}
+
+	for i := range records {
+		indices <- i
+	}
+	close(indices)
+	wg.Wait()
+
+	return errs
+}
+
+// verifyRecord checks r's tag on a fork of g, hashing the ciphertext as
+// Decrypt would but without generating any keystream.
+func (g *gcm) verifyRecord(r Record) error {
+	if err := Validate(r.Nonce, nil); err != nil {
+		return err
+	}
+
+	d := g.fork().newDecrypter(r.Nonce, nil)
+	d.endAD()
+	d.updateStream(r.Ciphertext)
+	d.ciphertextNb += uint64(len(r.Ciphertext))
+
+	return d.Verify(r.Tag)
+}
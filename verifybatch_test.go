@@ -0,0 +1,74 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func batchRecords(t testing.TB, n, size int) []Record {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	records := make([]Record, n)
+	for i := range records {
+		nonce := []byte(fmt.Sprintf("nonce%011d", i))
+		e := newGCMEncrypter(block, nonce, nil)
+		ciphertext := e.Encrypt(nil, make([]byte, size))
+		tag := e.Tag()
+		records[i] = Record{Nonce: nonce, Ciphertext: ciphertext, Tag: tag[:]}
+	}
+	return records
+}
+
+func TestVerifyBatch(t *testing.T) {
+	records := batchRecords(t, 50, 100)
+	records[3].Tag[0] ^= 1
+	records[17].Ciphertext[99] ^= 1
+	records[42].Nonce = nil
+
+	errs := VerifyBatch(key, records)
+	assert.Len(t, errs, len(records))
+	for i, err := range errs {
+		switch i {
+		case 3, 17:
+			assert.Equal(t, ErrOpen, err, "record %d", i)
+		case 42:
+			assert.Equal(t, errNonceSize, err)
+		default:
+			assert.Nil(t, err, "record %d", i)
+		}
+	}
+
+	errs = VerifyBatch(key[:5], records[:2])
+	assert.NotNil(t, errs[0])
+	assert.Equal(t, errs[0], errs[1])
+
+	assert.Empty(t, VerifyBatch(key, nil))
+}
+
+func BenchmarkVerifyBatch(b *testing.B) {
+	records := batchRecords(b, 256, 4096)
+
+	b.Run("parallel", func(b *testing.B) {
+		b.SetBytes(256 * 4096)
+		for i := 0; i < b.N; i++ {
+			VerifyBatch(key, records)
+		}
+	})
+	b.Run("serial", func(b *testing.B) {
+		b.SetBytes(256 * 4096)
+		block, _ := aes.NewCipher(key)
+		for i := 0; i < b.N; i++ {
+			for _, r := range records {
+				d := newGCMDecrypter(block, r.Nonce, nil)
+				d.Decrypt(nil, r.Ciphertext)
+				d.Verify(r.Tag)
+			}
+		}
+	})
+}
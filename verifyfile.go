@@ -0,0 +1,37 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"crypto/subtle"
+	"fmt"
+	"io"
+)
+
+// VerifyFile checks tag against the ciphertext read from r until EOF,
+// without decrypting it, so stored files can be checked for integrity
+// without ever producing their plaintext. Only GHASH runs over the
+// ciphertext; the block cipher is used just for the hash subkey and the tag
+// mask. It returns ErrOpen if the tag does not match, or the reader's error
+// if reading fails.
+func VerifyFile(cipher cipher.Block, nonce, additionalData []byte, r io.Reader, tag []byte) error {
+	a := NewAuthenticator(cipher, nonce, additionalData)
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		a.Update(buf[:n])
+
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("gcm: reading ciphertext: %w", err)
+		}
+	}
+
+	expected := a.Tag()
+	if subtle.ConstantTimeCompare(expected[:], tag) != 1 {
+		return ErrOpen
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyFile(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 100*1024+3)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	e := newGCMEncrypter(block, nonce, []byte("ad"))
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	path := filepath.Join(t.TempDir(), "ciphertext")
+	assert.Nil(t, os.WriteFile(path, ciphertext, 0o600))
+
+	verify := func(tag []byte) error {
+		f, err := os.Open(path)
+		assert.Nil(t, err)
+		defer f.Close()
+		return VerifyFile(block, nonce, []byte("ad"), f, tag)
+	}
+
+	assert.Nil(t, verify(tag[:]))
+
+	corrupted := tag
+	corrupted[3] ^= 0x40
+	assert.Equal(t, ErrOpen, verify(corrupted[:]))
+	assert.Equal(t, ErrOpen, verify(tag[:12]))
+
+	ciphertext[len(ciphertext)/2] ^= 1
+	assert.Nil(t, os.WriteFile(path, ciphertext, 0o600))
+	assert.Equal(t, ErrOpen, verify(tag[:]))
+}
+
+func TestVerifyFileReadError(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	failure := errors.New("disk error")
+	err = VerifyFile(block, nonce, nil, io.MultiReader(strings.NewReader("some ciphertext"), iotest.ErrReader(failure)), tag[:])
+	assert.ErrorIs(t, err, failure)
+}
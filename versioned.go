@@ -0,0 +1,63 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// versionV1 is the first versioned layout:
+// version || 16-byte nonce || ciphertext || 16-byte tag, with the version
+// byte authenticated ahead of the additional data.
+const versionV1 = 1
+
+// ErrUnsupportedVersion is returned by OpenVersioned for a blob whose
+// version byte it does not know.
+var ErrUnsupportedVersion = errors.New("gcm: unsupported blob version")
+
+// SealVersioned encrypts plaintext under a fresh random nonce and returns
+// version || nonce || ciphertext || tag in the current layout, version 1.
+// The version byte is authenticated, so it cannot be changed to make the
+// blob parse under a different layout.
+func SealVersioned(cipher cipher.Block, plaintext, additionalData []byte) ([]byte, error) {
+	ad := append([]byte{versionV1}, additionalData...)
+	g, nonce, err := newGCMEncrypterRandomNonce(cipher, nil, ad)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, 1+len(nonce)+len(plaintext)+gcmTagSize)
+	out = append(out, versionV1)
+	out = append(out, nonce...)
+	out = g.Encrypt(out, plaintext)
+	return g.Sum(out), nil
+}
+
+// OpenVersioned authenticates and decrypts a blob from SealVersioned,
+// choosing the layout by its version byte. It returns ErrUnsupportedVersion
+// for an unknown version and ErrOpen if the blob is malformed or fails
+// authentication. No plaintext is returned unless the tag is valid.
+func OpenVersioned(cipher cipher.Block, blob, additionalData []byte) ([]byte, error) {
+	if len(blob) == 0 {
+		return nil, ErrOpen
+	}
+
+	switch blob[0] {
+	case versionV1:
+		return openV1(cipher, blob, additionalData)
+	default:
+		return nil, ErrUnsupportedVersion
+	}
+}
+
+func openV1(cipher cipher.Block, blob, additionalData []byte) ([]byte, error) {
+	if len(blob) < 1+gcmNonceSize+gcmTagSize {
+		return nil, ErrOpen
+	}
+
+	nonce := blob[1 : 1+gcmNonceSize]
+	ciphertext := blob[1+gcmNonceSize : len(blob)-gcmTagSize]
+	tag := blob[len(blob)-gcmTagSize:]
+
+	ad := append([]byte{versionV1}, additionalData...)
+	return newGCMDecrypter(cipher, nonce, ad).OpenVerified(ciphertext, tag)
+}
@@ -0,0 +1,49 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVersionedRoundTrip(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	blob, err := SealVersioned(block, decryptedPacket, []byte("ad"))
+	assert.Nil(t, err)
+	assert.Equal(t, byte(versionV1), blob[0])
+	assert.Len(t, blob, 1+gcmNonceSize+len(decryptedPacket)+gcmTagSize)
+
+	plaintext, err := OpenVersioned(block, blob, []byte("ad"))
+	assert.Nil(t, err)
+	assert.Equal(t, decryptedPacket, plaintext)
+
+	_, err = OpenVersioned(block, blob, []byte("other"))
+	assert.Equal(t, ErrOpen, err)
+
+	blob[len(blob)-1] ^= 1
+	_, err = OpenVersioned(block, blob, []byte("ad"))
+	assert.Equal(t, ErrOpen, err)
+}
+
+func TestVersionedErrors(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	blob, err := SealVersioned(block, nil, nil)
+	assert.Nil(t, err)
+
+	for _, version := range []byte{0, 2, 0xff} {
+		unknown := append([]byte{}, blob...)
+		unknown[0] = version
+		_, err = OpenVersioned(block, unknown, nil)
+		assert.Equal(t, ErrUnsupportedVersion, err)
+	}
+
+	_, err = OpenVersioned(block, nil, nil)
+	assert.Equal(t, ErrOpen, err)
+	_, err = OpenVersioned(block, blob[:len(blob)-1], nil)
+	assert.Equal(t, ErrOpen, err)
+}
@@ -0,0 +1,40 @@
+package uncheckedgcm
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+var errWebCryptoNonceSize = errors.New("gcm: WebCrypto helpers require a 12-byte nonce")
+
+// SealWebCrypto encrypts plaintext with the layout produced by the browser
+// SubtleCrypto.encrypt for AES-GCM with a 12-byte iv and the default 128-bit
+// tagLength: the ciphertext followed by the tag.
+func SealWebCrypto(block cipher.Block, nonce, plaintext, additionalData []byte) []byte {
+	if len(nonce) != gcmStandardNonceSize {
+		panic(errWebCryptoNonceSize)
+	}
+
+	g := newGCMEncrypter(block, nonce, additionalData)
+	out := g.Encrypt(make([]byte, 0, len(plaintext)+gcmTagSize), plaintext)
+
+	tag := g.Tag()
+	return append(out, tag[:]...)
+}
+
+// OpenWebCrypto decrypts the output of SubtleCrypto.encrypt for AES-GCM with
+// a 12-byte iv and the default 128-bit tagLength. The plaintext is only
+// returned once the trailing tag has been verified.
+func OpenWebCrypto(block cipher.Block, nonce, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(nonce) != gcmStandardNonceSize {
+		return nil, errWebCryptoNonceSize
+	}
+	if len(ciphertext) < gcmTagSize {
+		return nil, ErrOpen
+	}
+
+	tag := ciphertext[len(ciphertext)-gcmTagSize:]
+	ciphertext = ciphertext[:len(ciphertext)-gcmTagSize]
+
+	return newGCMDecrypter(block, nonce, additionalData).OpenDetached(ciphertext, tag)
+}
@@ -0,0 +1,83 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	assert.Nil(t, err)
+	return b
+}
+
+// The output of
+//
+//	crypto.subtle.encrypt({name: "AES-GCM", iv, additionalData}, key, plaintext)
+//
+// for test case 4 of the original GCM specification, which is what every
+// conforming WebCrypto implementation produces.
+func TestWebCryptoConformance(t *testing.T) {
+	block, err := aes.NewCipher(decodeHex(t, "feffe9928665731c6d6a8f9467308308"))
+	assert.Nil(t, err)
+
+	iv := decodeHex(t, "cafebabefacedbaddecaf888")
+	additionalData := decodeHex(t, "feedfacedeadbeeffeedfacedeadbeefabaddad2")
+	plaintext := decodeHex(t, "d9313225f88406e5a55909c5aff5269a86a7a9531534f7da2e4c303d8a318a72"+
+		"1c3c0c95956809532fcf0e2449a6b525b16aedf5aa0de657ba637b39")
+	sealed := decodeHex(t, "42831ec2217774244b7221b784d0d49ce3aa212f2c02a4e035c17e2329aca12e"+
+		"21d514b25466931c7d8f6a5aac84aa051ba30b396a0aac973d58e091"+
+		"5bc94fbc3221a5db94fae95ae7121a47")
+
+	assert.Equal(t, sealed, SealWebCrypto(block, iv, plaintext, additionalData))
+
+	opened, err := OpenWebCrypto(block, iv, sealed, additionalData)
+	assert.Nil(t, err)
+	assert.Equal(t, plaintext, opened)
+
+	sealed[len(sealed)-1] ^= 1
+	_, err = OpenWebCrypto(block, iv, sealed, additionalData)
+	assert.ErrorIs(t, err, ErrOpen)
+}
+
+func TestWebCryptoStdlib(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	aead, err := cipher.NewGCM(block)
+	assert.Nil(t, err)
+
+	iv := nonce[:gcmStandardNonceSize]
+	additionalData := []byte("header")
+
+	for n := 0; n <= 3*gcmBlockSize; n++ {
+		plaintext := make([]byte, n)
+		for i := range plaintext {
+			plaintext[i] = byte(i)
+		}
+
+		sealed := aead.Seal(nil, iv, plaintext, additionalData)
+		assert.Equal(t, sealed, SealWebCrypto(block, iv, plaintext, additionalData))
+
+		opened, err := OpenWebCrypto(block, iv, sealed, additionalData)
+		assert.Nil(t, err)
+		assert.Equal(t, n, len(opened))
+	}
+}
+
+func TestWebCryptoInvalid(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	assert.Panics(t, func() { SealWebCrypto(block, nonce, nil, nil) })
+
+	_, err = OpenWebCrypto(block, nonce, make([]byte, 32), nil)
+	assert.ErrorIs(t, err, errWebCryptoNonceSize)
+
+	_, err = OpenWebCrypto(block, nonce[:12], make([]byte, 15), nil)
+	assert.ErrorIs(t, err, ErrOpen)
+}
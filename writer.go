@@ -0,0 +1,50 @@
+package uncheckedgcm
+
+import "io"
+
+// decryptToBufferSize is the plaintext buffered per Write by DecryptTo.
+const decryptToBufferSize = 64 * gcmBlockSize
+
+// DecryptTo decrypts ciphertext in pieces through a small buffer and writes
+// the plaintext to w, so the whole plaintext never has to be held in memory.
+// It returns the number of plaintext bytes written. If w fails part way
+// through, the rest of ciphertext is still processed, so Verify covers all
+// of it and the decrypter stays positioned after it.
+func (g *Decrypter) DecryptTo(w io.Writer, ciphertext []byte) (int, error) {
+	var buf [decryptToBufferSize]byte
+	written := 0
+
+	for len(ciphertext) > 0 {
+		chunk := ciphertext[:min(len(ciphertext), len(buf))]
+		ciphertext = ciphertext[len(chunk):]
+
+		plaintext, err := g.Decrypt(buf[:0], chunk)
+		if err != nil {
+			return written, err
+		}
+
+		n, err := w.Write(plaintext)
+		written += n
+		if err == nil && n < len(plaintext) {
+			err = io.ErrShortWrite
+		}
+		if err != nil {
+			g.skip(buf[:], ciphertext)
+			return written, err
+		}
+	}
+
+	clear(buf[:])
+	return written, nil
+}
+
+// skip processes ciphertext as Decrypt would, using buf as scratch space and
+// discarding the plaintext.
+func (g *Decrypter) skip(buf, ciphertext []byte) {
+	for len(ciphertext) > 0 {
+		chunk := ciphertext[:min(len(ciphertext), len(buf))]
+		ciphertext = ciphertext[len(chunk):]
+		g.Decrypt(buf[:0], chunk)
+	}
+	clear(buf)
+}
@@ -0,0 +1,84 @@
+package uncheckedgcm
+
+import (
+	"bytes"
+	"crypto/aes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// shortWriter accepts at most limit bytes in total.
+type shortWriter struct {
+	bytes.Buffer
+	limit int
+	err   error
+}
+
+func (w *shortWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > w.limit {
+		n, _ := w.Buffer.Write(p[:w.limit-w.Len()])
+		return n, w.err
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestDecryptTo(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 3*decryptToBufferSize+7)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	d := newGCMDecrypter(block, nonce, nil)
+	var out bytes.Buffer
+	n, err := d.DecryptTo(&out, ciphertext[:100])
+	assert.Nil(t, err)
+	assert.Equal(t, 100, n)
+	n, err = d.DecryptTo(&out, ciphertext[100:])
+	assert.Nil(t, err)
+	assert.Equal(t, len(ciphertext)-100, n)
+
+	assert.Equal(t, plaintext, out.Bytes())
+	assert.Nil(t, d.VerifyArray(tag))
+}
+
+func TestDecryptToShortWrite(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	plaintext := make([]byte, 3*decryptToBufferSize)
+	for i := range plaintext {
+		plaintext[i] = byte(i)
+	}
+
+	e := newGCMEncrypter(block, nonce, nil)
+	ciphertext := e.Encrypt(nil, plaintext)
+	tag := e.Tag()
+
+	errBroken := errors.New("broken pipe")
+	for _, writeErr := range []error{nil, errBroken} {
+		d := newGCMDecrypter(block, nonce, nil)
+		w := &shortWriter{limit: decryptToBufferSize + 10, err: writeErr}
+
+		n, err := d.DecryptTo(w, ciphertext)
+		if writeErr == nil {
+			assert.ErrorIs(t, err, io.ErrShortWrite)
+		} else {
+			assert.ErrorIs(t, err, errBroken)
+		}
+		assert.Equal(t, w.limit, n)
+		assert.Equal(t, plaintext[:n], w.Bytes())
+
+		// The tag still covers the whole ciphertext.
+		assert.Nil(t, d.VerifyArray(tag))
+	}
+}
@@ -0,0 +1,64 @@
+package uncheckedgcm
+
+// EncryptZeros appends the encryption of n zero bytes to dst and returns the
+// updated slice. The ciphertext of zeros is the keystream itself, so it is
+// written straight into dst with no zero buffer and no XOR. The result and
+// the tag are the same as Encrypt with a zero-filled plaintext.
+func (g *Encrypter) EncryptZeros(dst []byte, n int) []byte {
+	g.mustBeLive()
+	if g.finalized {
+		panic(ErrFinalized)
+	}
+	if n < 0 {
+		panic("gcm: negative length")
+	}
+	if err := g.checkKeystream(n); err != nil {
+		panic(err)
+	}
+
+	ret, out := sliceForAppend(dst, n)
+	if g.macPlaintext {
+		// The zeros themselves are hashed, so they must exist.
+		clear(out)
+		return g.Encrypt(dst, out)
+	}
+
+	g.endAD()
+	if g.onCheckpoint == nil {
+		g.encryptZeros(out)
+		return ret
+	}
+
+	for i := 0; i < n; {
+		j := i + g.untilCheckpoint(g.plaintextNb, n-i)
+		g.encryptZeros(out[i:j])
+		g.checkpoint(g.additionalDataNb, g.plaintextNb)
+		i = j
+	}
+	return ret
+}
+
+func (g *Encrypter) encryptZeros(out []byte) {
+	g.keystreamTo(out)
+	g.updateStream(out)
+	g.plaintextNb += uint64(len(out))
+}
+
+// keystreamTo fills out with the next len(out) bytes of keystream, using up
+// any leftover from earlier calls first and keeping what is left of the
+// final block, exactly as counterCrypt does.
+func (g *gcm) keystreamTo(out []byte) {
+	n := copy(out, g.extraMask)
+	g.extraMask = g.extraMask[n:]
+	out = out[n:]
+
+	fullBlocks := (len(out) >> 4) << 4
+	g.keystream(out[:fullBlocks], &g.counter)
+	out = out[fullBlocks:]
+
+	if len(out) > 0 {
+		g.keystream(g.mask[:gcmBlockSize], &g.counter)
+		n := copy(out, g.mask[:gcmBlockSize])
+		g.extraMask = g.mask[n:gcmBlockSize]
+	}
+}
@@ -0,0 +1,40 @@
+package uncheckedgcm
+
+import (
+	"crypto/aes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptZeros(t *testing.T) {
+	block, err := aes.NewCipher(key)
+	assert.Nil(t, err)
+
+	sizes := []int{0, 1, 15, 16, 17, 5, 40, 3}
+
+	for _, newEnc := range []func() *Encrypter{
+		func() *Encrypter { return newGCMEncrypter(block, nonce, []byte("ad")) },
+		func() *Encrypter { return newGCMEncrypter(&bulkBlock{Block: block}, nonce, []byte("ad")) },
+		func() *Encrypter { return newGCMEncrypterMtE(block, nonce, []byte("ad")) },
+	} {
+		e := newEnc()
+		reference := newEnc()
+
+		// Interleave with ordinary Encrypt calls so leftover keystream
+		// is carried across both.
+		var got, want []byte
+		for i, n := range sizes {
+			if i%3 == 2 {
+				got = e.Encrypt(got, decryptedPacket[:n%len(decryptedPacket)])
+				want = reference.Encrypt(want, decryptedPacket[:n%len(decryptedPacket)])
+				continue
+			}
+			got = e.EncryptZeros(got, n)
+			want = reference.Encrypt(want, make([]byte, n))
+		}
+
+		assert.Equal(t, want, got)
+		assert.Equal(t, reference.Tag(), e.Tag())
+	}
+}